@@ -0,0 +1,238 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// defaultBoltBucket 默认的 bucket 名称
+const defaultBoltBucket = "wechat_cache"
+
+// DefaultBoltCleanupInterval 建议的 bbolt 缓存后台清理间隔
+const DefaultBoltCleanupInterval = time.Minute
+
+//Bolt 基于 bbolt 的本地持久化缓存，适用于没有 redis/memcache 的单实例部署
+//每次写入都在事务中完成并同步落盘，进程崩溃或重启后票据和 token 不会丢失
+type Bolt struct {
+	db     *bolt.DB
+	bucket []byte
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+//BoltOpts bbolt 缓存配置
+type BoltOpts struct {
+	Path            string        // 数据文件路径，不存在时自动创建
+	Bucket          string        // bucket 名称，默认 wechat_cache
+	OpenTimeout     time.Duration // 等待文件锁的超时时间，0 使用默认值 1 秒，避免多个进程同时打开时一直阻塞
+	CleanupInterval time.Duration // 后台清理过期数据的间隔，与 MemoryOpts 一致，大于 0 时启动后台清理，否则过期数据只在读取时忽略、不会从文件中删除
+}
+
+// boltItem 保存在 bbolt 中的数据
+type boltItem struct {
+	Data    json.RawMessage `json:"data"`
+	Expired int64           `json:"expired,omitempty"` // 过期时间的 unix 纳秒，0 表示永不过期
+}
+
+func (item *boltItem) expired(now time.Time) bool {
+	return item.Expired != 0 && now.UnixNano() >= item.Expired
+}
+
+//NewBolt 打开或创建 bbolt 缓存，不再使用时需调用 Close
+func NewBolt(opts *BoltOpts) (*Bolt, error) {
+	bucket := opts.Bucket
+	if bucket == "" {
+		bucket = defaultBoltBucket
+	}
+	openTimeout := opts.OpenTimeout
+	if openTimeout == 0 {
+		openTimeout = time.Second
+	}
+	db, err := bolt.Open(opts.Path, 0600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("open bolt cache %s error, err=%v", opts.Path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	b := &Bolt{
+		db:     db,
+		bucket: []byte(bucket),
+		stop:   make(chan struct{}),
+	}
+	if opts.CleanupInterval > 0 {
+		go b.janitor(opts.CleanupInterval)
+	}
+	return b, nil
+}
+
+//Get 获取一个值
+func (b *Bolt) Get(key string) interface{} {
+	val, _, _ := b.GetContext(context.Background(), key)
+	return val
+}
+
+//Set 设置一个值，timeout 为 0 表示永不过期
+func (b *Bolt) Set(key string, val interface{}, timeout time.Duration) error {
+	return b.SetContext(context.Background(), key, val, timeout)
+}
+
+//IsExist 判断key是否存在
+func (b *Bolt) IsExist(key string) bool {
+	ok, _ := b.IsExistContext(context.Background(), key)
+	return ok
+}
+
+//Delete 删除
+func (b *Bolt) Delete(key string) error {
+	return b.DeleteContext(context.Background(), key)
+}
+
+//GetContext 获取一个值，读取或解码失败时返回 error
+func (b *Bolt) GetContext(ctx context.Context, key string) (val interface{}, found bool, err error) {
+	item, err := b.load(ctx, key)
+	if err != nil || item == nil {
+		return
+	}
+	if err = json.Unmarshal(item.Data, &val); err != nil {
+		return
+	}
+	found = true
+	return
+}
+
+//SetContext 设置一个值，timeout 为 0 表示永不过期
+func (b *Bolt) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	item := boltItem{Data: data}
+	if timeout > 0 {
+		item.Expired = time.Now().Add(timeout).UnixNano()
+	}
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(key), raw)
+	})
+}
+
+//IsExistContext 判断key是否存在
+func (b *Bolt) IsExistContext(ctx context.Context, key string) (bool, error) {
+	item, err := b.load(ctx, key)
+	return item != nil, err
+}
+
+//DeleteContext 删除
+func (b *Bolt) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Delete([]byte(key))
+	})
+}
+
+//TTLContext 返回 key 的剩余有效期，小于 0 表示永不过期，等于 0 表示 key 不存在
+func (b *Bolt) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	item, err := b.load(ctx, key)
+	if err != nil || item == nil {
+		return 0, err
+	}
+	if item.Expired == 0 {
+		return -1, nil
+	}
+	return time.Until(time.Unix(0, item.Expired)), nil
+}
+
+//Close 停止后台清理并关闭数据文件
+func (b *Bolt) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		close(b.stop)
+		err = b.db.Close()
+	})
+	return err
+}
+
+// load 读取未过期的数据，不存在或已过期时返回 nil
+func (b *Bolt) load(ctx context.Context, key string) (*boltItem, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var raw []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		// bbolt 返回的数据只在事务内有效，需要复制
+		if v := tx.Bucket(b.bucket).Get([]byte(key)); v != nil {
+			raw = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	if err != nil || raw == nil {
+		return nil, err
+	}
+	item := new(boltItem)
+	if err = json.Unmarshal(raw, item); err != nil {
+		return nil, fmt.Errorf("decode bolt cache %s error, err=%v", key, err)
+	}
+	if item.expired(time.Now()) {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// deleteExpired 清理所有过期数据
+func (b *Bolt) deleteExpired() error {
+	now := time.Now()
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(b.bucket)
+		var expiredKeys [][]byte
+		err := bucket.ForEach(func(k, v []byte) error {
+			var item boltItem
+			// 无法解码的数据同样清理
+			if json.Unmarshal(v, &item) != nil || item.expired(now) {
+				expiredKeys = append(expiredKeys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expiredKeys {
+			if err = bucket.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *Bolt) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			b.deleteExpired()
+		case <-b.stop:
+			return
+		}
+	}
+}
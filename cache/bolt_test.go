@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBolt(t *testing.T) {
+	var b *Bolt
+	dir, err := ioutil.TempDir("", "wechat_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.db")
+	b, err = NewBolt(&BoltOpts{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = b.Set("ticket", "verify_ticket", 0); err != nil {
+		t.Error("set Error", err)
+	}
+	if err = b.Set("token", "access_token", 20*time.Millisecond); err != nil {
+		t.Error("set Error", err)
+	}
+	if !b.IsExist("token") {
+		t.Error("IsExist Error")
+	}
+	time.Sleep(30 * time.Millisecond)
+	if b.Get("token") != nil {
+		t.Error("expired value should be nil")
+	}
+	if err = b.deleteExpired(); err != nil {
+		t.Error("deleteExpired Error", err)
+	}
+	b.Close()
+
+	// 重新打开后数据仍然存在
+	b, err = NewBolt(&BoltOpts{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if v, _ := b.Get("ticket").(string); v != "verify_ticket" {
+		t.Errorf("get after reopen = %q", v)
+	}
+	if err = b.Delete("ticket"); err != nil || b.IsExist("ticket") {
+		t.Errorf("delete Error , err=%v", err)
+	}
+}
+
+func TestBoltJanitor(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wechat_cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	b, err := NewBolt(&BoltOpts{Path: filepath.Join(dir, "cache.db"), CleanupInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	b.Set("token", "access_token", time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	b.db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(b.bucket).Get([]byte("token")) != nil {
+			t.Error("expired data should be removed by janitor")
+		}
+		return nil
+	})
+}
@@ -4,9 +4,11 @@ import "time"
 
 const (
 	// ComponentVerifyTicket 票据
-	ComponentVerifyTicket = "component_verify_ticket_%s"
+	// Deprecated: 不支持命名空间，请使用 KeyBuilder.ComponentVerifyTicket
+	ComponentVerifyTicket = KeyFamilyComponentVerifyTicket + "_%s"
 	// ComponentAccessToken 开放平台apitoken
-	ComponentAccessToken = "component_access_token_%s"
+	// Deprecated: 不支持命名空间，请使用 KeyBuilder.ComponentAccessToken
+	ComponentAccessToken = KeyFamilyComponentAccessToken + "_%s"
 )
 
 //Cache interface
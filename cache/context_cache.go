@@ -0,0 +1,90 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+//ContextCache 支持 context 及错误返回的缓存接口
+//与 Cache 不同，后端故障会以 error 返回，不会被当作未命中
+type ContextCache interface {
+	//GetContext 获取值，key 不存在时 found 为 false 且 err 为 nil
+	GetContext(ctx context.Context, key string) (val interface{}, found bool, err error)
+	SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error
+	IsExistContext(ctx context.Context, key string) (bool, error)
+	DeleteContext(ctx context.Context, key string) error
+}
+
+//WithContext 将 Cache 转换为 ContextCache
+//若已实现 ContextCache 则直接返回，否则包装一层适配器，此时无法区分后端故障与未命中
+func WithContext(c Cache) ContextCache {
+	if cc, ok := c.(ContextCache); ok {
+		return cc
+	}
+	return &contextAdapter{c}
+}
+
+type contextAdapter struct {
+	Cache
+}
+
+func (a *contextAdapter) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	val := a.Get(key)
+	return val, val != nil, nil
+}
+
+func (a *contextAdapter) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.Set(key, val, timeout)
+}
+
+func (a *contextAdapter) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return a.IsExist(key), nil
+}
+
+func (a *contextAdapter) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return a.Delete(key)
+}
+
+//GetString 获取字符串类型的值，值存在但类型不符时返回错误
+func GetString(ctx context.Context, c ContextCache, key string) (string, bool, error) {
+	val, found, err := c.GetContext(ctx, key)
+	if err != nil || !found {
+		return "", false, err
+	}
+	switch v := val.(type) {
+	case string:
+		return v, true, nil
+	case []byte:
+		return string(v), true, nil
+	}
+	return "", false, fmt.Errorf("cache value of %s is %T, not string", key, val)
+}
+
+//GetBytes 获取 []byte 类型的值，值存在但类型不符时返回错误
+//注意 Redis、Memcache 以 JSON 保存值，写入的 []byte 读出时为 base64 字符串，建议存取字符串
+func GetBytes(ctx context.Context, c ContextCache, key string) ([]byte, bool, error) {
+	val, found, err := c.GetContext(ctx, key)
+	if err != nil || !found {
+		return nil, false, err
+	}
+	switch v := val.(type) {
+	case []byte:
+		return v, true, nil
+	case string:
+		return []byte(v), true, nil
+	}
+	return nil, false, fmt.Errorf("cache value of %s is %T, not bytes", key, val)
+}
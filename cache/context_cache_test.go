@@ -0,0 +1,43 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type plainCache struct {
+	Cache
+}
+
+func TestWithContext(t *testing.T) {
+	mem := NewMemory()
+	defer mem.Close()
+
+	if _, ok := WithContext(mem).(*Memory); !ok {
+		t.Error("Memory should be used as ContextCache directly")
+	}
+
+	cc := WithContext(plainCache{mem})
+	ctx := context.Background()
+	if err := cc.SetContext(ctx, "token", "abc", time.Minute); err != nil {
+		t.Error("set Error", err)
+	}
+	if v, found, err := GetString(ctx, cc, "token"); err != nil || !found || v != "abc" {
+		t.Errorf("GetString = %q, %v, %v", v, found, err)
+	}
+	if _, found, err := GetString(ctx, cc, "missing"); err != nil || found {
+		t.Errorf("missing key should not be found, err=%v", err)
+	}
+
+	mem.Set("number", 1, time.Minute)
+	if _, _, err := GetBytes(ctx, cc, "number"); err == nil {
+		t.Error("type mismatch should return error")
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, _, err := cc.GetContext(canceled, "token"); err == nil {
+		t.Error("canceled context should return error")
+	}
+}
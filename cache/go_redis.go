@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+//GoRedis 基于 go-redis 的缓存，支持单机、集群及哨兵模式
+type GoRedis struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+//GoRedisOpts go-redis 连接属性
+type GoRedisOpts struct {
+	// Addrs 单个地址为单机模式，多个地址为集群模式，设置 MasterName 时为哨兵地址
+	Addrs            []string      `yml:"addrs" json:"addrs"`
+	MasterName       string        `yml:"master_name" json:"master_name"` // 哨兵模式的 master 名称
+	Username         string        `yml:"username" json:"username"`
+	Password         string        `yml:"password" json:"password"`
+	SentinelPassword string        `yml:"sentinel_password" json:"sentinel_password"`
+	Database         int           `yml:"database" json:"database"` // 集群模式下无效
+	PoolSize         int           `yml:"pool_size" json:"pool_size"`
+	MinIdleConns     int           `yml:"min_idle_conns" json:"min_idle_conns"`
+	DialTimeout      time.Duration `yml:"dial_timeout" json:"dial_timeout"`
+	ReadTimeout      time.Duration `yml:"read_timeout" json:"read_timeout"`
+	WriteTimeout     time.Duration `yml:"write_timeout" json:"write_timeout"`
+	KeyPrefix        string        `yml:"key_prefix" json:"key_prefix"` // 所有 key 的前缀
+	TLSConfig        *tls.Config   `yml:"-" json:"-"`                   // 不为 nil 时使用 TLS 连接
+}
+
+//NewGoRedis 实例化
+func NewGoRedis(opts *GoRedisOpts) *GoRedis {
+	client := redis.NewUniversalClient(&redis.UniversalOptions{
+		Addrs:            opts.Addrs,
+		MasterName:       opts.MasterName,
+		Username:         opts.Username,
+		Password:         opts.Password,
+		SentinelPassword: opts.SentinelPassword,
+		DB:               opts.Database,
+		PoolSize:         opts.PoolSize,
+		MinIdleConns:     opts.MinIdleConns,
+		DialTimeout:      opts.DialTimeout,
+		ReadTimeout:      opts.ReadTimeout,
+		WriteTimeout:     opts.WriteTimeout,
+		TLSConfig:        opts.TLSConfig,
+	})
+	return NewGoRedisWithClient(client, opts.KeyPrefix)
+}
+
+//NewGoRedisWithClient 使用已有的 go-redis 客户端
+func NewGoRedisWithClient(client redis.UniversalClient, prefix string) *GoRedis {
+	return &GoRedis{client: client, prefix: prefix}
+}
+
+//Client 返回底层的 go-redis 客户端
+func (r *GoRedis) Client() redis.UniversalClient {
+	return r.client
+}
+
+//Close 关闭连接
+func (r *GoRedis) Close() error {
+	return r.client.Close()
+}
+
+func (r *GoRedis) key(key string) string {
+	return r.prefix + key
+}
+
+//Get 获取一个值
+func (r *GoRedis) Get(key string) interface{} {
+	val, _, _ := r.GetContext(context.Background(), key)
+	return val
+}
+
+//Set 设置一个值，timeout 为 0 表示永不过期
+func (r *GoRedis) Set(key string, val interface{}, timeout time.Duration) error {
+	return r.SetContext(context.Background(), key, val, timeout)
+}
+
+//IsExist 判断key是否存在
+func (r *GoRedis) IsExist(key string) bool {
+	ok, _ := r.IsExistContext(context.Background(), key)
+	return ok
+}
+
+//Delete 删除
+func (r *GoRedis) Delete(key string) error {
+	return r.DeleteContext(context.Background(), key)
+}
+
+//GetContext 获取一个值，redis 故障时返回 error
+func (r *GoRedis) GetContext(ctx context.Context, key string) (val interface{}, found bool, err error) {
+	data, err := r.client.Get(ctx, r.key(key)).Bytes()
+	if err == redis.Nil {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &val); err != nil {
+		return
+	}
+	found = true
+	return
+}
+
+//SetContext 设置一个值，timeout 为 0 表示永不过期
+func (r *GoRedis) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, r.key(key), data, timeout).Err()
+}
+
+//SetNX key 不存在时才设置，返回是否设置成功，可用于分布式锁
+func (r *GoRedis) SetNX(ctx context.Context, key string, val interface{}, timeout time.Duration) (bool, error) {
+	data, err := json.Marshal(val)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetNX(ctx, r.key(key), data, timeout).Result()
+}
+
+//IsExistContext 判断key是否存在，redis 故障时返回 error
+func (r *GoRedis) IsExistContext(ctx context.Context, key string) (bool, error) {
+	n, err := r.client.Exists(ctx, r.key(key)).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+//DeleteContext 删除
+func (r *GoRedis) DeleteContext(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.key(key)).Err()
+}
+
+//TTLContext 返回 key 的剩余有效期，小于 0 表示永不过期，等于 0 表示 key 不存在
+func (r *GoRedis) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := r.client.PTTL(ctx, r.key(key)).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch ttl {
+	case -1:
+		return -1, nil
+	case -2:
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+//NewInvalidator 基于 redis 发布订阅的缓存失效通知，用于 Layered 的多实例本地副本同步
+func (r *GoRedis) NewInvalidator(channel string) Invalidator {
+	return &goRedisInvalidator{
+		client:  r.client,
+		channel: r.key(channel),
+		id:      randomID(),
+	}
+}
+
+// goRedisInvalidator 消息格式为 "实例ID key"，忽略本实例发出的消息
+type goRedisInvalidator struct {
+	client  redis.UniversalClient
+	channel string
+	id      string
+}
+
+func (i *goRedisInvalidator) Invalidate(ctx context.Context, key string) error {
+	return i.client.Publish(ctx, i.channel, i.id+" "+key).Err()
+}
+
+func (i *goRedisInvalidator) Subscribe(onInvalidate func(key string)) (func() error, error) {
+	ctx := context.Background()
+	pubsub := i.client.Subscribe(ctx, i.channel)
+	// 等待订阅确认，确保返回后不会漏掉消息
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, err
+	}
+	go func() {
+		for msg := range pubsub.Channel() {
+			parts := strings.SplitN(msg.Payload, " ", 2)
+			if len(parts) != 2 || parts[0] == i.id {
+				continue
+			}
+			onInvalidate(parts[1])
+		}
+	}()
+	return pubsub.Close, nil
+}
+
+func randomID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
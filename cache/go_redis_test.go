@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGoRedis(t *testing.T) {
+	r := NewGoRedis(&GoRedisOpts{
+		Addrs:     []string{"127.0.0.1:6379"},
+		KeyPrefix: "wechat_test:",
+	})
+	defer r.Close()
+	ctx := context.Background()
+	if err := r.Client().Ping(ctx).Err(); err != nil {
+		t.Skipf("redis is not available, err=%v", err)
+	}
+
+	var err error
+	if err = r.Set("username", "silenceper", time.Second); err != nil {
+		t.Error("set Error", err)
+	}
+	if !r.IsExist("username") {
+		t.Error("IsExist Error")
+	}
+	if name, _ := r.Get("username").(string); name != "silenceper" {
+		t.Error("get Error")
+	}
+	if ok, err := r.SetNX(ctx, "username", "other", time.Second); err != nil || ok {
+		t.Errorf("SetNX on existing key should fail, ok=%v, err=%v", ok, err)
+	}
+	if err = r.Delete("username"); err != nil {
+		t.Errorf("delete Error , err=%v", err)
+	}
+}
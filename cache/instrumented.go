@@ -0,0 +1,161 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// 缓存操作
+const (
+	OpGet     = "get"
+	OpSet     = "set"
+	OpIsExist = "is_exist"
+	OpDelete  = "delete"
+	OpTTL     = "ttl"
+	OpSetNX   = "set_nx"
+)
+
+// 缓存操作结果
+const (
+	ResultHit   = "hit"   // 读取命中
+	ResultMiss  = "miss"  // 读取未命中
+	ResultOK    = "ok"    // 写入/删除成功
+	ResultError = "error" // 后端故障
+)
+
+// KeyFamilyOther 无法识别类别的 key
+const KeyFamilyOther = "other"
+
+// ErrNotSupported 被包装的缓存不支持该操作
+var ErrNotSupported = errors.New("cache: operation not supported by backend")
+
+//Metrics 缓存指标收集接口
+type Metrics interface {
+	//ObserveCache 记录一次缓存操作，family 为 key 的类别（见 KeyFamily*），op 为 Op*，result 为 Result*
+	ObserveCache(family, op, result string, duration time.Duration)
+}
+
+//Instrumented 为 Cache 增加指标上报的装饰器
+//被包装的缓存支持 TTLContext、SetNX 时同样可通过装饰器调用，可作为 Layered 的共享缓存
+type Instrumented struct {
+	backend Cache
+	cache   ContextCache
+	metrics Metrics
+	keys    KeyBuilder
+}
+
+//NewInstrumented 包装 Cache，namespace 需与 Config.CacheNamespace 一致以便识别 key 的类别
+func NewInstrumented(c Cache, metrics Metrics, namespace string) *Instrumented {
+	return &Instrumented{
+		backend: c,
+		cache:   WithContext(c),
+		metrics: metrics,
+		keys:    NewKeyBuilder(namespace),
+	}
+}
+
+//Get 获取一个值
+func (i *Instrumented) Get(key string) interface{} {
+	val, _, _ := i.GetContext(context.Background(), key)
+	return val
+}
+
+//Set 设置一个值
+func (i *Instrumented) Set(key string, val interface{}, timeout time.Duration) error {
+	return i.SetContext(context.Background(), key, val, timeout)
+}
+
+//IsExist 判断key是否存在
+func (i *Instrumented) IsExist(key string) bool {
+	ok, _ := i.IsExistContext(context.Background(), key)
+	return ok
+}
+
+//Delete 删除
+func (i *Instrumented) Delete(key string) error {
+	return i.DeleteContext(context.Background(), key)
+}
+
+//GetContext 获取一个值
+func (i *Instrumented) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	start := time.Now()
+	val, found, err := i.cache.GetContext(ctx, key)
+	i.observe(key, OpGet, lookupResult(found, err), start)
+	return val, found, err
+}
+
+//SetContext 设置一个值
+func (i *Instrumented) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	start := time.Now()
+	err := i.cache.SetContext(ctx, key, val, timeout)
+	i.observe(key, OpSet, writeResult(err), start)
+	return err
+}
+
+//IsExistContext 判断key是否存在
+func (i *Instrumented) IsExistContext(ctx context.Context, key string) (bool, error) {
+	start := time.Now()
+	ok, err := i.cache.IsExistContext(ctx, key)
+	i.observe(key, OpIsExist, lookupResult(ok, err), start)
+	return ok, err
+}
+
+//DeleteContext 删除
+func (i *Instrumented) DeleteContext(ctx context.Context, key string) error {
+	start := time.Now()
+	err := i.cache.DeleteContext(ctx, key)
+	i.observe(key, OpDelete, writeResult(err), start)
+	return err
+}
+
+//TTLContext 返回 key 的剩余有效期，被包装的缓存不支持时返回 ErrNotSupported
+func (i *Instrumented) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	ttlCache, ok := i.backend.(TTLCache)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	start := time.Now()
+	ttl, err := ttlCache.TTLContext(ctx, key)
+	i.observe(key, OpTTL, lookupResult(ttl != 0, err), start)
+	return ttl, err
+}
+
+//SetNX key 不存在时才设置，被包装的缓存不支持时返回 ErrNotSupported
+func (i *Instrumented) SetNX(ctx context.Context, key string, val interface{}, timeout time.Duration) (bool, error) {
+	setNX, ok := i.backend.(interface {
+		SetNX(ctx context.Context, key string, val interface{}, timeout time.Duration) (bool, error)
+	})
+	if !ok {
+		return false, ErrNotSupported
+	}
+	start := time.Now()
+	ok, err := setNX.SetNX(ctx, key, val, timeout)
+	i.observe(key, OpSetNX, writeResult(err), start)
+	return ok, err
+}
+
+func (i *Instrumented) observe(key, op, result string, start time.Time) {
+	family, _, ok := i.keys.Parse(key)
+	if !ok {
+		family = KeyFamilyOther
+	}
+	i.metrics.ObserveCache(family, op, result, time.Since(start))
+}
+
+func lookupResult(found bool, err error) string {
+	switch {
+	case err != nil:
+		return ResultError
+	case found:
+		return ResultHit
+	}
+	return ResultMiss
+}
+
+func writeResult(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultOK
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type recordMetrics map[string]int
+
+func (r recordMetrics) ObserveCache(family, op, result string, duration time.Duration) {
+	r[family+"/"+op+"/"+result]++
+}
+
+func TestInstrumented(t *testing.T) {
+	mem := NewMemory()
+	defer mem.Close()
+	metrics := recordMetrics{}
+	c := NewInstrumented(mem, metrics, "prod")
+	keys := NewKeyBuilder("prod")
+
+	c.Get(keys.AccessToken("wx1"))
+	c.Set(keys.AccessToken("wx1"), "token", time.Minute)
+	c.Get(keys.AccessToken("wx1"))
+	c.Get(keys.AuthorizerAccessToken("wx2"))
+	c.Delete("custom")
+
+	want := map[string]int{
+		"access_token/get/miss":            1,
+		"access_token/set/ok":              1,
+		"access_token/get/hit":             1,
+		"authorizer_access_token/get/miss": 1,
+		"other/delete/ok":                  1,
+	}
+	for k, n := range want {
+		if metrics[k] != n {
+			t.Errorf("%s = %d, want %d", k, metrics[k], n)
+		}
+	}
+}
+
+func TestInstrumentedTTL(t *testing.T) {
+	mem := NewMemory()
+	metrics := recordMetrics{}
+	c := NewInstrumented(mem, metrics, "")
+	c.Set("token", "v", time.Minute)
+	if ttl, err := c.TTLContext(context.Background(), "token"); err != nil || ttl <= 0 {
+		t.Fatalf("ttl = %v, err = %v", ttl, err)
+	}
+	if metrics["other/ttl/hit"] != 1 {
+		t.Errorf("ttl should be observed, metrics = %v", metrics)
+	}
+
+	// Layered 通过装饰器获取远程剩余有效期，仍保存本地副本
+	l, err := NewLayered(c, &LayeredOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	l.Get("token")
+	if l.local.Len() != 1 {
+		t.Error("layered over instrumented should keep local copy")
+	}
+
+	if _, err = NewInstrumented(noTTLRemote{mem}, metrics, "").TTLContext(context.Background(), "token"); err != ErrNotSupported {
+		t.Errorf("err = %v, want ErrNotSupported", err)
+	}
+}
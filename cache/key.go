@@ -0,0 +1,116 @@
+package cache
+
+import "strings"
+
+// 缓存 key 的类别，完整的 key 为 [命名空间:]类别_ID
+const (
+	KeyFamilyAccessToken               = "access_token"
+	KeyFamilyQyAccessToken             = "qy_access_token"
+	KeyFamilyJsAPITicket               = "jsapi_ticket"
+	KeyFamilyComponentVerifyTicket     = "component_verify_ticket"
+	KeyFamilyComponentVerifyTicketTime = "component_verify_ticket_time" // 票据的接收时间
+	KeyFamilyComponentAccessToken      = "component_access_token"
+	KeyFamilyAuthorizerAccessToken     = "authorizer_access_token"
+	KeyFamilyAuthorizer                = "authorizer_info" // 授权方信息（refresh_token 等）
+	KeyFamilyPaySandboxSignKey         = "pay_sandbox_signkey"
+	KeyFamilyReleaseState              = "release_state" // 第三方平台批量发布中单个小程序的状态
+)
+
+// keyFamilies 解析时按顺序匹配，较长的类别需在以其为前缀的类别之前
+var keyFamilies = []string{
+	KeyFamilyComponentVerifyTicketTime,
+	KeyFamilyComponentVerifyTicket,
+	KeyFamilyComponentAccessToken,
+	KeyFamilyAuthorizerAccessToken,
+	KeyFamilyAuthorizer,
+	KeyFamilyPaySandboxSignKey,
+	KeyFamilyReleaseState,
+	KeyFamilyQyAccessToken,
+	KeyFamilyJsAPITicket,
+	KeyFamilyAccessToken,
+}
+
+//KeyBuilder 统一构造缓存 key
+//Namespace 为空时与旧版本的 key 保持一致，多个环境或租户共用一个缓存时设置不同的 Namespace 隔离
+type KeyBuilder struct {
+	Namespace string
+}
+
+//NewKeyBuilder 创建 key 构造器
+func NewKeyBuilder(namespace string) KeyBuilder {
+	return KeyBuilder{Namespace: namespace}
+}
+
+//Build 构造 类别_ID 格式的 key
+func (b KeyBuilder) Build(family, id string) string {
+	if b.Namespace == "" {
+		return family + "_" + id
+	}
+	return b.Namespace + ":" + family + "_" + id
+}
+
+//Parse 从 key 中解析出类别和 ID，命名空间不匹配或类别未知时 ok 为 false
+func (b KeyBuilder) Parse(key string) (family, id string, ok bool) {
+	if b.Namespace != "" {
+		if !strings.HasPrefix(key, b.Namespace+":") {
+			return "", "", false
+		}
+		key = key[len(b.Namespace)+1:]
+	}
+	for _, f := range keyFamilies {
+		if strings.HasPrefix(key, f+"_") {
+			return f, key[len(f)+1:], true
+		}
+	}
+	return "", "", false
+}
+
+//AccessToken 公众号/小程序 access_token
+func (b KeyBuilder) AccessToken(appID string) string {
+	return b.Build(KeyFamilyAccessToken, appID)
+}
+
+//QyAccessToken 企业微信 access_token
+func (b KeyBuilder) QyAccessToken(appID string) string {
+	return b.Build(KeyFamilyQyAccessToken, appID)
+}
+
+//JsAPITicket js-sdk 的 jsapi_ticket
+func (b KeyBuilder) JsAPITicket(appID string) string {
+	return b.Build(KeyFamilyJsAPITicket, appID)
+}
+
+//ComponentVerifyTicket 第三方平台票据
+func (b KeyBuilder) ComponentVerifyTicket(componentAppID string) string {
+	return b.Build(KeyFamilyComponentVerifyTicket, componentAppID)
+}
+
+//ComponentVerifyTicketTime 第三方平台票据的接收时间
+func (b KeyBuilder) ComponentVerifyTicketTime(componentAppID string) string {
+	return b.Build(KeyFamilyComponentVerifyTicketTime, componentAppID)
+}
+
+//ComponentAccessToken 第三方平台 component_access_token
+func (b KeyBuilder) ComponentAccessToken(componentAppID string) string {
+	return b.Build(KeyFamilyComponentAccessToken, componentAppID)
+}
+
+//AuthorizerAccessToken 授权方 authorizer_access_token
+func (b KeyBuilder) AuthorizerAccessToken(authorizerAppID string) string {
+	return b.Build(KeyFamilyAuthorizerAccessToken, authorizerAppID)
+}
+
+//Authorizer 第三方平台授权方信息
+func (b KeyBuilder) Authorizer(authorizerAppID string) string {
+	return b.Build(KeyFamilyAuthorizer, authorizerAppID)
+}
+
+//PaySandboxSignKey 支付仿真测试的沙箱密钥
+func (b KeyBuilder) PaySandboxSignKey(mchID string) string {
+	return b.Build(KeyFamilyPaySandboxSignKey, mchID)
+}
+
+//ReleaseState 第三方平台批量发布任务中单个小程序的状态
+func (b KeyBuilder) ReleaseState(taskID, authorizerAppID string) string {
+	return b.Build(KeyFamilyReleaseState, taskID+"_"+authorizerAppID)
+}
@@ -0,0 +1,31 @@
+package cache
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestKeyBuilder(t *testing.T) {
+	if key := NewKeyBuilder("").ComponentVerifyTicket("wx1"); key != fmt.Sprintf(ComponentVerifyTicket, "wx1") {
+		t.Errorf("empty namespace should keep legacy key, got %s", key)
+	}
+
+	b := NewKeyBuilder("prod")
+	key := b.QyAccessToken("wx1")
+	if key != "prod:qy_access_token_wx1" {
+		t.Errorf("QyAccessToken = %s", key)
+	}
+	family, id, ok := b.Parse(key)
+	if !ok || family != KeyFamilyQyAccessToken || id != "wx1" {
+		t.Errorf("Parse(%s) = %s, %s, %v", key, family, id, ok)
+	}
+	if _, _, ok := NewKeyBuilder("staging").Parse(key); ok {
+		t.Error("key of other namespace should not be parsed")
+	}
+	if family, id, _ := b.Parse(b.ComponentVerifyTicketTime("wx1")); family != KeyFamilyComponentVerifyTicketTime || id != "wx1" {
+		t.Errorf("Parse ticket time = %s, %s", family, id)
+	}
+	if family, _, _ := b.Parse(b.AccessToken("wx1")); family != KeyFamilyAccessToken {
+		t.Errorf("Parse access token family = %s", family)
+	}
+}
@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// defaultLocalTTL 本地副本默认最长保留时间
+// 微信刷新 access_token 后旧 token 仍有 5 分钟有效期，1 分钟的本地副本不会导致使用失效的 token
+const defaultLocalTTL = time.Minute
+
+//TTLCache 可查询剩余有效期的缓存
+type TTLCache interface {
+	//TTLContext 返回 key 的剩余有效期，小于 0 表示永不过期，等于 0 表示 key 不存在
+	TTLContext(ctx context.Context, key string) (time.Duration, error)
+}
+
+//Invalidator 在多个实例间广播缓存失效消息
+type Invalidator interface {
+	//Invalidate 通知其它实例 key 已失效
+	Invalidate(ctx context.Context, key string) error
+	//Subscribe 订阅其它实例发出的失效消息，返回取消订阅的函数
+	Subscribe(onInvalidate func(key string)) (cancel func() error, err error)
+}
+
+//Layered 两级缓存，在共享缓存前保留一份短期的本地副本，减少热点 key 的远程访问
+//共享缓存需支持查询剩余有效期（TTLCache），否则不保存本地副本，避免本地副本比共享缓存存活更久
+type Layered struct {
+	// mu 保护 generation，使失效与本地副本的写入互斥
+	mu sync.Mutex
+	// generation 每次失效时递增，读取共享缓存期间发生过失效则不保存本地副本
+	generation uint64
+
+	local       *Memory
+	remote      ContextCache
+	ttlCache    TTLCache
+	localTTL    time.Duration
+	invalidator Invalidator
+	unsubscribe func() error
+}
+
+//LayeredOpts 两级缓存配置
+type LayeredOpts struct {
+	LocalTTL        time.Duration // 本地副本最长保留时间，0 使用默认值 1 分钟；远程剩余有效期更短时以远程为准
+	LocalMaxEntries int           // 本地副本最大条数，0 表示不限制
+	// Invalidator 不为 nil 时，本实例写入/删除 key 会通知其它实例删除本地副本
+	Invalidator Invalidator
+}
+
+//NewLayered 创建两级缓存，remote 为 Redis 等共享缓存
+func NewLayered(remote Cache, opts *LayeredOpts) (*Layered, error) {
+	l := &Layered{
+		local:       NewMemoryWithOpts(&MemoryOpts{MaxEntries: opts.LocalMaxEntries}),
+		remote:      WithContext(remote),
+		localTTL:    opts.LocalTTL,
+		invalidator: opts.Invalidator,
+	}
+	if l.localTTL <= 0 {
+		l.localTTL = defaultLocalTTL
+	}
+	if ttlCache, ok := remote.(TTLCache); ok {
+		l.ttlCache = ttlCache
+	}
+	if l.invalidator != nil {
+		unsubscribe, err := l.invalidator.Subscribe(l.deleteLocal)
+		if err != nil {
+			l.local.Close()
+			return nil, err
+		}
+		l.unsubscribe = unsubscribe
+	}
+	return l, nil
+}
+
+//Get 获取一个值
+func (l *Layered) Get(key string) interface{} {
+	val, _, _ := l.GetContext(context.Background(), key)
+	return val
+}
+
+//Set 设置一个值
+func (l *Layered) Set(key string, val interface{}, timeout time.Duration) error {
+	return l.SetContext(context.Background(), key, val, timeout)
+}
+
+//IsExist 判断key是否存在
+func (l *Layered) IsExist(key string) bool {
+	ok, _ := l.IsExistContext(context.Background(), key)
+	return ok
+}
+
+//Delete 删除
+func (l *Layered) Delete(key string) error {
+	return l.DeleteContext(context.Background(), key)
+}
+
+//GetContext 先读本地副本，未命中时读取共享缓存并保存本地副本
+func (l *Layered) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	if val, found, _ := l.local.GetContext(ctx, key); found {
+		return val, true, nil
+	}
+	l.mu.Lock()
+	generation := l.generation
+	l.mu.Unlock()
+	val, found, err := l.remote.GetContext(ctx, key)
+	if err != nil || !found {
+		return val, found, err
+	}
+	if l.ttlCache == nil {
+		return val, true, nil
+	}
+	remoteTTL, err := l.ttlCache.TTLContext(ctx, key)
+	if err != nil || remoteTTL == 0 {
+		// 无法确认剩余有效期时不保存本地副本
+		return val, true, nil
+	}
+	ttl := l.localTTL
+	if remoteTTL > 0 && remoteTTL < ttl {
+		ttl = remoteTTL
+	}
+	l.mu.Lock()
+	if l.generation == generation {
+		l.local.Set(key, val, ttl)
+	}
+	l.mu.Unlock()
+	return val, true, nil
+}
+
+//SetContext 写入共享缓存并使各实例的本地副本失效
+//本地副本在下次读取时从共享缓存加载，保证与其它实例读到的类型一致
+func (l *Layered) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	if err := l.remote.SetContext(ctx, key, val, timeout); err != nil {
+		return err
+	}
+	return l.invalidate(ctx, key)
+}
+
+//IsExistContext 判断key是否存在
+func (l *Layered) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if l.local.IsExist(key) {
+		return true, nil
+	}
+	return l.remote.IsExistContext(ctx, key)
+}
+
+//DeleteContext 删除共享缓存并使各实例的本地副本失效
+func (l *Layered) DeleteContext(ctx context.Context, key string) error {
+	l.deleteLocal(key)
+	if err := l.remote.DeleteContext(ctx, key); err != nil {
+		return err
+	}
+	return l.invalidate(ctx, key)
+}
+
+//Close 取消失效订阅并停止本地副本的后台清理，不会关闭共享缓存
+func (l *Layered) Close() error {
+	l.local.Close()
+	if l.unsubscribe != nil {
+		return l.unsubscribe()
+	}
+	return nil
+}
+
+func (l *Layered) invalidate(ctx context.Context, key string) error {
+	l.deleteLocal(key)
+	if l.invalidator == nil {
+		return nil
+	}
+	return l.invalidator.Invalidate(ctx, key)
+}
+
+// deleteLocal 删除本地副本，并使正在读取共享缓存的请求不再保存本地副本
+func (l *Layered) deleteLocal(key string) {
+	l.mu.Lock()
+	l.generation++
+	l.local.Delete(key)
+	l.mu.Unlock()
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBus 进程内的失效通知，模拟多个实例
+type memoryBus struct {
+	mu   sync.Mutex
+	subs []func(key string)
+}
+
+type busInvalidator struct {
+	bus  *memoryBus
+	self int
+}
+
+func (b *busInvalidator) Invalidate(ctx context.Context, key string) error {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+	for i, sub := range b.bus.subs {
+		if i != b.self {
+			sub(key)
+		}
+	}
+	return nil
+}
+
+func (b *busInvalidator) Subscribe(onInvalidate func(key string)) (func() error, error) {
+	b.bus.mu.Lock()
+	defer b.bus.mu.Unlock()
+	b.self = len(b.bus.subs)
+	b.bus.subs = append(b.bus.subs, onInvalidate)
+	return func() error { return nil }, nil
+}
+
+func TestLayered(t *testing.T) {
+	remote := NewMemory()
+	defer remote.Close()
+	bus := &memoryBus{}
+
+	a, err := NewLayered(remote, &LayeredOpts{LocalTTL: time.Minute, Invalidator: &busInvalidator{bus: bus}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+	b, _ := NewLayered(remote, &LayeredOpts{LocalTTL: time.Minute, Invalidator: &busInvalidator{bus: bus}})
+	defer b.Close()
+
+	a.Set("token", "v1", time.Hour)
+	if v, _ := b.Get("token").(string); v != "v1" {
+		t.Errorf("get = %q, want v1", v)
+	}
+	// b 已有本地副本，a 刷新后 b 的副本应失效
+	a.Set("token", "v2", time.Hour)
+	if v, _ := b.Get("token").(string); v != "v2" {
+		t.Errorf("get after invalidation = %q, want v2", v)
+	}
+
+	// 本地副本不应超过远程剩余有效期
+	a.Set("short", "v", 30*time.Millisecond)
+	a.Get("short")
+	time.Sleep(50 * time.Millisecond)
+	if a.Get("short") != nil {
+		t.Error("local copy should respect remote ttl")
+	}
+
+	b.Get("token")
+	a.Delete("token")
+	if b.IsExist("token") {
+		t.Error("delete should invalidate other instances")
+	}
+}
+
+// racingRemote 在读取共享缓存后、返回前执行 onGet，模拟读取期间其它请求写入
+type racingRemote struct {
+	*Memory
+	onGet func()
+}
+
+func (r *racingRemote) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	val, found, err := r.Memory.GetContext(ctx, key)
+	if r.onGet != nil {
+		onGet := r.onGet
+		r.onGet = nil
+		onGet()
+	}
+	return val, found, err
+}
+
+func TestLayeredSkipStaleFill(t *testing.T) {
+	remote := &racingRemote{Memory: NewMemory()}
+	l, err := NewLayered(remote, &LayeredOpts{LocalTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	remote.Set("token", "v1", time.Hour)
+	remote.onGet = func() {
+		l.Set("token", "v2", time.Hour)
+	}
+	if v, _ := l.Get("token").(string); v != "v1" {
+		t.Fatalf("get = %q, want v1", v)
+	}
+	if v, _ := l.Get("token").(string); v != "v2" {
+		t.Errorf("stale value should not be cached locally, got %q", v)
+	}
+}
+
+// noTTLRemote 不支持查询剩余有效期的共享缓存
+type noTTLRemote struct {
+	Cache
+}
+
+func TestLayeredWithoutRemoteTTL(t *testing.T) {
+	remote := NewMemory()
+	l, err := NewLayered(noTTLRemote{remote}, &LayeredOpts{LocalTTL: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	l.Set("token", "v1", time.Hour)
+	l.Get("token")
+	if l.local.Len() != 0 {
+		t.Error("local copy should not be kept without remote ttl")
+	}
+}
@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -55,3 +56,48 @@ func (mem *Memcache) Set(key string, val interface{}, timeout time.Duration) (er
 func (mem *Memcache) Delete(key string) error {
 	return mem.conn.Delete(key)
 }
+
+//GetContext return cached value, memcache 故障时返回 error
+func (mem *Memcache) GetContext(ctx context.Context, key string) (val interface{}, found bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	item, err := mem.conn.Get(key)
+	if err == memcache.ErrCacheMiss {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(item.Value, &val); err != nil {
+		return
+	}
+	found = true
+	return
+}
+
+//SetContext cached value with key and expire time.
+func (mem *Memcache) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return mem.Set(key, val, timeout)
+}
+
+//IsExistContext check value exists in memcache, memcache 故障时返回 error
+func (mem *Memcache) IsExistContext(ctx context.Context, key string) (bool, error) {
+	_, found, err := mem.GetContext(ctx, key)
+	return found, err
+}
+
+//DeleteContext delete value in memcache, key 不存在不视为错误
+func (mem *Memcache) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := mem.conn.Delete(key); err != nil && err != memcache.ErrCacheMiss {
+		return err
+	}
+	return nil
+}
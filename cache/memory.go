@@ -1,74 +1,232 @@
 package cache
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
 )
 
-//Memory struct contains *memcache.Client
+//Memory 内存缓存，并发安全，支持过期清理和 LRU 淘汰
 type Memory struct {
 	sync.Mutex
 
-	data map[string]*data
+	data       map[string]*list.Element
+	lru        *list.List // 最近使用的在前
+	maxEntries int
+
+	stop      chan struct{}
+	closeOnce sync.Once
+}
+
+//MemoryOpts 内存缓存配置
+type MemoryOpts struct {
+	MaxEntries      int           // 最大缓存条数，超出时淘汰最久未使用的数据，0 表示不限制
+	CleanupInterval time.Duration // 后台清理过期数据的间隔，大于 0 时启动后台清理，否则只在访问时删除过期数据
 }
 
 type data struct {
+	Key     string
 	Data    interface{}
-	Expired time.Time
+	Expired time.Time // 零值表示永不过期
+}
+
+func (d *data) expired(now time.Time) bool {
+	return !d.Expired.IsZero() && !now.Before(d.Expired)
 }
 
-//NewMemory create new memcache
+//NewMemory create new memory cache，不启动后台清理
 func NewMemory() *Memory {
-	return &Memory{
-		data: map[string]*data{},
+	return NewMemoryWithOpts(&MemoryOpts{})
+}
+
+//NewMemoryWithOpts 按配置创建内存缓存，设置了 CleanupInterval 时不再使用需调用 Close 停止后台清理
+func NewMemoryWithOpts(opts *MemoryOpts) *Memory {
+	mem := &Memory{
+		data:       map[string]*list.Element{},
+		lru:        list.New(),
+		maxEntries: opts.MaxEntries,
+		stop:       make(chan struct{}),
 	}
+	if opts.CleanupInterval > 0 {
+		go mem.janitor(opts.CleanupInterval)
+	}
+	return mem
 }
 
 //Get return cached value
 func (mem *Memory) Get(key string) interface{} {
-	if ret, ok := mem.data[key]; ok {
-		if ret.Expired.Before(time.Now()) {
-			mem.deleteKey(key)
-			return nil
-		}
-		return ret.Data
+	mem.Lock()
+	defer mem.Unlock()
+
+	if e := mem.get(key); e != nil {
+		return e.Value.(*data).Data
 	}
 	return nil
 }
 
-// IsExist check value exists in memcache.
+// IsExist check value exists in memory cache.
 func (mem *Memory) IsExist(key string) bool {
-	if ret, ok := mem.data[key]; ok {
-		if ret.Expired.Before(time.Now()) {
-			mem.deleteKey(key)
-			return false
-		}
-		return true
+	mem.Lock()
+	defer mem.Unlock()
+
+	return mem.get(key) != nil
+}
+
+//GetContext return cached value
+func (mem *Memory) GetContext(ctx context.Context, key string) (interface{}, bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, false, err
+	}
+	mem.Lock()
+	defer mem.Unlock()
+
+	if e := mem.get(key); e != nil {
+		return e.Value.(*data).Data, true, nil
 	}
-	return false
+	return nil, false, nil
 }
 
-//Set cached value with key and expire time.
+//IsExistContext check value exists in memory cache.
+func (mem *Memory) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	return mem.IsExist(key), nil
+}
+
+//SetContext cached value with key and expire time.
+func (mem *Memory) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return mem.Set(key, val, timeout)
+}
+
+//DeleteContext delete value in memory cache.
+func (mem *Memory) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return mem.Delete(key)
+}
+
+//TTLContext 返回 key 的剩余有效期，小于 0 表示永不过期，等于 0 表示 key 不存在
+func (mem *Memory) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	mem.Lock()
+	defer mem.Unlock()
+
+	e := mem.get(key)
+	if e == nil {
+		return 0, nil
+	}
+	d := e.Value.(*data)
+	if d.Expired.IsZero() {
+		return -1, nil
+	}
+	return time.Until(d.Expired), nil
+}
+
+//Set cached value with key and expire time, timeout 为 0 表示永不过期
 func (mem *Memory) Set(key string, val interface{}, timeout time.Duration) (err error) {
 	mem.Lock()
 	defer mem.Unlock()
 
-	mem.data[key] = &data{
+	var expired time.Time
+	if timeout > 0 {
+		expired = time.Now().Add(timeout)
+	}
+	if e, ok := mem.data[key]; ok {
+		d := e.Value.(*data)
+		d.Data = val
+		d.Expired = expired
+		mem.lru.MoveToFront(e)
+		return nil
+	}
+	mem.data[key] = mem.lru.PushFront(&data{
+		Key:     key,
 		Data:    val,
-		Expired: time.Now().Add(timeout),
+		Expired: expired,
+	})
+	if mem.maxEntries > 0 {
+		for mem.lru.Len() > mem.maxEntries {
+			mem.removeElement(mem.lru.Back())
+		}
 	}
 	return nil
 }
 
-//Delete delete value in memcache.
+//Delete delete value in memory cache.
 func (mem *Memory) Delete(key string) error {
-	return mem.deleteKey(key)
+	mem.Lock()
+	defer mem.Unlock()
+
+	if e, ok := mem.data[key]; ok {
+		mem.removeElement(e)
+	}
+	return nil
 }
 
-// deleteKey
-func (mem *Memory) deleteKey(key string) error {
+//Len 当前缓存条数（包含已过期但尚未清理的数据）
+func (mem *Memory) Len() int {
 	mem.Lock()
 	defer mem.Unlock()
-	delete(mem.data, key)
+
+	return mem.lru.Len()
+}
+
+//Close 停止后台清理，可重复调用
+func (mem *Memory) Close() error {
+	mem.closeOnce.Do(func() {
+		close(mem.stop)
+	})
 	return nil
 }
+
+// get 返回未过期的数据并标记为最近使用，调用方需持有锁
+func (mem *Memory) get(key string) *list.Element {
+	e, ok := mem.data[key]
+	if !ok {
+		return nil
+	}
+	if e.Value.(*data).expired(time.Now()) {
+		mem.removeElement(e)
+		return nil
+	}
+	mem.lru.MoveToFront(e)
+	return e
+}
+
+func (mem *Memory) removeElement(e *list.Element) {
+	mem.lru.Remove(e)
+	delete(mem.data, e.Value.(*data).Key)
+}
+
+// deleteExpired 清理所有过期数据
+func (mem *Memory) deleteExpired() {
+	mem.Lock()
+	defer mem.Unlock()
+
+	now := time.Now()
+	for _, e := range mem.data {
+		if e.Value.(*data).expired(now) {
+			mem.removeElement(e)
+		}
+	}
+}
+
+func (mem *Memory) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			mem.deleteExpired()
+		case <-mem.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package cache
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	mem := NewMemoryWithOpts(&MemoryOpts{CleanupInterval: 10 * time.Millisecond})
+	defer mem.Close()
+
+	if err := mem.Set("forever", "ticket", 0); err != nil {
+		t.Error("set Error", err)
+	}
+	if err := mem.Set("short", "token", 20*time.Millisecond); err != nil {
+		t.Error("set Error", err)
+	}
+	if !mem.IsExist("forever") || !mem.IsExist("short") {
+		t.Error("IsExist Error")
+	}
+	if v, _ := mem.Get("forever").(string); v != "ticket" {
+		t.Error("get Error")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if mem.Get("short") != nil {
+		t.Error("expired value should be nil")
+	}
+	if !mem.IsExist("forever") {
+		t.Error("timeout 0 should never expire")
+	}
+	if mem.Len() != 1 {
+		t.Errorf("janitor should remove expired value, len=%d", mem.Len())
+	}
+
+	if err := mem.Delete("forever"); err != nil || mem.IsExist("forever") {
+		t.Errorf("delete Error , err=%v", err)
+	}
+}
+
+func TestMemoryLRU(t *testing.T) {
+	mem := NewMemoryWithOpts(&MemoryOpts{MaxEntries: 2, CleanupInterval: 0})
+	defer mem.Close()
+
+	mem.Set("a", 1, 0)
+	mem.Set("b", 2, 0)
+	mem.Get("a")
+	mem.Set("c", 3, 0)
+	if mem.IsExist("b") {
+		t.Error("least recently used key should be evicted")
+	}
+	if !mem.IsExist("a") || !mem.IsExist("c") {
+		t.Error("recently used keys should be kept")
+	}
+}
+
+func TestMemoryConcurrent(t *testing.T) {
+	mem := NewMemory()
+	defer mem.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key_%d", j%10)
+				mem.Set(key, i, time.Millisecond)
+				mem.Get(key)
+				mem.IsExist(key)
+				mem.Delete(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestNewMemoryWithoutJanitor(t *testing.T) {
+	// Memory 保持导出的 Lock/Unlock
+	var _ sync.Locker = NewMemory()
+
+	before := runtime.NumGoroutine()
+	for i := 0; i < 10; i++ {
+		NewMemory()
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("NewMemory should not start goroutines, before=%d after=%d", before, after)
+	}
+}
@@ -0,0 +1,5 @@
+module github.com/pengshang1995/wechat-sdk/cache/prometheus
+
+go 1.14
+
+require github.com/prometheus/client_golang v1.11.1
@@ -0,0 +1,53 @@
+// Package prometheus 将缓存指标上报到 Prometheus，为独立的 module，不使用时 SDK 不依赖 client_golang
+package prometheus
+
+import (
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+//Metrics 实现 cache.Metrics
+type Metrics struct {
+	requests *prom.CounterVec
+	duration *prom.HistogramVec
+}
+
+//NewMetrics 创建指标并注册到 registerer，registerer 为 nil 时使用 prometheus.DefaultRegisterer
+//指标：
+//  <namespace>_cache_requests_total{family,op,result}
+//  <namespace>_cache_request_duration_seconds{family,op}
+func NewMetrics(namespace string, registerer prom.Registerer) (*Metrics, error) {
+	if registerer == nil {
+		registerer = prom.DefaultRegisterer
+	}
+	m := &Metrics{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "requests_total",
+			Help:      "Number of cache operations by key family, operation and result.",
+		}, []string{"family", "op", "result"}),
+		duration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "cache",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of cache backend operations.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"family", "op"}),
+	}
+	if err := registerer.Register(m.requests); err != nil {
+		return nil, err
+	}
+	if err := registerer.Register(m.duration); err != nil {
+		registerer.Unregister(m.requests)
+		return nil, err
+	}
+	return m, nil
+}
+
+//ObserveCache 记录一次缓存操作
+func (m *Metrics) ObserveCache(family, op, result string, duration time.Duration) {
+	m.requests.WithLabelValues(family, op, result).Inc()
+	m.duration.WithLabelValues(family, op).Observe(duration.Seconds())
+}
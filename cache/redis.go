@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"encoding/json"
 	"time"
 
@@ -93,12 +94,11 @@ func (r *Redis) IsExist(key string) bool {
 	conn := r.conn.Get()
 	defer conn.Close()
 
-	a, _ := conn.Do("EXISTS", key)
-	i := a.(int64)
-	if i > 0 {
-		return true
+	i, err := redis.Int64(conn.Do("EXISTS", key))
+	if err != nil {
+		return false
 	}
-	return false
+	return i > 0
 }
 
 //Delete 删除
@@ -112,3 +112,78 @@ func (r *Redis) Delete(key string) error {
 
 	return nil
 }
+
+//GetContext 获取一个值，redis 故障时返回 error
+func (r *Redis) GetContext(ctx context.Context, key string) (val interface{}, found bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+	conn := r.conn.Get()
+	defer conn.Close()
+
+	data, err := redis.Bytes(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &val); err != nil {
+		return
+	}
+	found = true
+	return
+}
+
+//SetContext 设置一个值
+func (r *Redis) SetContext(ctx context.Context, key string, val interface{}, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.Set(key, val, timeout)
+}
+
+//IsExistContext 判断key是否存在，redis 故障时返回 error
+func (r *Redis) IsExistContext(ctx context.Context, key string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	conn := r.conn.Get()
+	defer conn.Close()
+
+	i, err := redis.Int64(conn.Do("EXISTS", key))
+	if err != nil {
+		return false, err
+	}
+	return i > 0, nil
+}
+
+//DeleteContext 删除
+func (r *Redis) DeleteContext(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return r.Delete(key)
+}
+
+//TTLContext 返回 key 的剩余有效期，小于 0 表示永不过期，等于 0 表示 key 不存在
+func (r *Redis) TTLContext(ctx context.Context, key string) (time.Duration, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	conn := r.conn.Get()
+	defer conn.Close()
+
+	ms, err := redis.Int64(conn.Do("PTTL", key))
+	if err != nil {
+		return 0, err
+	}
+	switch ms {
+	case -1:
+		return -1, nil
+	case -2:
+		return 0, nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
@@ -43,10 +43,9 @@ func (ctx *Context) GetAccessToken() (accessToken string, err error) {
 	if ctx.accessTokenFunc != nil {
 		return ctx.accessTokenFunc(ctx)
 	}
-	accessTokenCacheKey := fmt.Sprintf("access_token_%s", ctx.AppID)
-	val := ctx.Cache.Get(accessTokenCacheKey)
-	if val != nil {
-		accessToken = val.(string)
+	accessTokenCacheKey := ctx.CacheKeys().AccessToken(ctx.AppID)
+	var found bool
+	if accessToken, found, err = ctx.getCacheString(accessTokenCacheKey); err != nil || found {
 		return
 	}
 
@@ -61,6 +60,12 @@ func (ctx *Context) GetAccessToken() (accessToken string, err error) {
 	return
 }
 
+//InvalidateAccessToken 删除缓存的 access_token，接口返回 40001、42001 等 token 失效的错误时调用
+//使用 cache.Layered 时同时通知其它实例删除本地副本，下次 GetAccessToken 重新获取
+func (ctx *Context) InvalidateAccessToken() error {
+	return ctx.Cache.Delete(ctx.CacheKeys().AccessToken(ctx.AppID))
+}
+
 //GetAccessTokenFromServer 强制从微信服务器获取token
 func (ctx *Context) GetAccessTokenFromServer() (resAccessToken ResAccessToken, err error) {
 	url := fmt.Sprintf("%s?grant_type=client_credential&appid=%s&secret=%s", AccessTokenURL, ctx.AppID, ctx.AppSecret)
@@ -78,7 +83,7 @@ func (ctx *Context) GetAccessTokenFromServer() (resAccessToken ResAccessToken, e
 		return
 	}
 
-	accessTokenCacheKey := fmt.Sprintf("access_token_%s", ctx.AppID)
+	accessTokenCacheKey := ctx.CacheKeys().AccessToken(ctx.AppID)
 	expires := resAccessToken.ExpiresIn - 1500
 	err = ctx.Cache.Set(accessTokenCacheKey, resAccessToken.AccessToken, time.Duration(expires)*time.Second)
 	return
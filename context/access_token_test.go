@@ -3,6 +3,9 @@ package context
 import (
 	"sync"
 	"testing"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/cache"
 )
 
 func TestContext_SetCustomAccessTokenFunc(t *testing.T) {
@@ -28,3 +31,27 @@ func TestContext_NoSetCustomAccessTokenFunc(t *testing.T) {
 		t.Error("error accessTokenFunc")
 	}
 }
+
+func TestContext_InvalidateAccessToken(t *testing.T) {
+	remote := cache.NewMemory()
+	local, err := cache.NewLayered(remote, &cache.LayeredOpts{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer local.Close()
+	ctx := Context{
+		AppID:           "wx1",
+		Cache:           local,
+		accessTokenLock: new(sync.RWMutex),
+	}
+	local.Set(ctx.CacheKeys().AccessToken("wx1"), "token", time.Hour)
+	if res, err := ctx.GetAccessToken(); res != "token" || err != nil {
+		t.Fatalf("expect cached token, got %q %v", res, err)
+	}
+	if err = ctx.InvalidateAccessToken(); err != nil {
+		t.Fatal(err)
+	}
+	if local.IsExist(ctx.CacheKeys().AccessToken("wx1")) || remote.IsExist(ctx.CacheKeys().AccessToken("wx1")) {
+		t.Error("token should be removed from both layers")
+	}
+}
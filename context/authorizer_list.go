@@ -0,0 +1,181 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+const (
+	getAuthorizerListURL  = "https://api.weixin.qq.com/cgi-bin/component/api_get_authorizer_list?component_access_token=%s"
+	setComponentConfigURL = "https://api.weixin.qq.com/cgi-bin/component/api_set_authorizer_option?component_access_token=%s"
+
+	// maxAuthorizerListCount 拉取授权方列表每页最大数量
+	maxAuthorizerListCount = 500
+)
+
+// AuthorizerOption 授权方选项名称
+type AuthorizerOption string
+
+const (
+	// OptionLocationReport 地理位置上报，0 无上报 1 进入会话时上报 2 每 5s 上报
+	OptionLocationReport AuthorizerOption = "location_report"
+	// OptionVoiceRecognize 语音识别开关，0 关闭 1 开启
+	OptionVoiceRecognize AuthorizerOption = "voice_recognize"
+	// OptionCustomerService 多客服开关，0 关闭 1 开启
+	OptionCustomerService AuthorizerOption = "customer_service"
+)
+
+// AuthorizerListItem 授权方列表中的授权方
+type AuthorizerListItem struct {
+	AuthorizerAppID string `json:"authorizer_appid"`
+	RefreshToken    string `json:"refresh_token"`
+	AuthTime        int64  `json:"auth_time"` // 授权时间，unix 秒
+}
+
+// AuthorizerList 授权方列表
+type AuthorizerList struct {
+	util.CommonError
+	TotalCount int                  `json:"total_count"`
+	List       []AuthorizerListItem `json:"list"`
+}
+
+// GetAuthorizerList 分页拉取已授权的帐号列表，count 最大为 500
+func (ctx *Context) GetAuthorizerList(offset, count int) (*AuthorizerList, error) {
+	if count <= 0 || count > maxAuthorizerListCount {
+		count = maxAuthorizerListCount
+	}
+	req := map[string]interface{}{
+		"component_appid": ctx.AppID,
+		"offset":          offset,
+		"count":           count,
+	}
+	ret := &AuthorizerList{}
+	if err := ctx.postComponent(getAuthorizerListURL, req, ret); err != nil {
+		return nil, err
+	}
+	if ret.ErrCode != 0 {
+		return nil, fmt.Errorf("GetAuthorizerList error : errcode=%v , errmsg=%v", ret.ErrCode, ret.ErrMsg)
+	}
+	return ret, nil
+}
+
+// AuthorizerIterator 遍历全部授权方，按页拉取
+//
+//	it := ctx.NewAuthorizerIterator(100)
+//	for it.Next() {
+//		item := it.Authorizer()
+//	}
+//	if err := it.Err(); err != nil {}
+type AuthorizerIterator struct {
+	ctx      *Context
+	pageSize int
+	offset   int
+	total    int
+	page     []AuthorizerListItem
+	index    int
+	err      error
+	done     bool
+}
+
+// NewAuthorizerIterator 创建授权方迭代器，pageSize 为每页数量，最大 500
+func (ctx *Context) NewAuthorizerIterator(pageSize int) *AuthorizerIterator {
+	if pageSize <= 0 || pageSize > maxAuthorizerListCount {
+		pageSize = maxAuthorizerListCount
+	}
+	return &AuthorizerIterator{ctx: ctx, pageSize: pageSize, index: -1}
+}
+
+// Next 移动到下一个授权方，没有更多数据或出错时返回 false
+func (it *AuthorizerIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	it.index++
+	if it.index < len(it.page) {
+		return true
+	}
+	if it.done {
+		return false
+	}
+	list, err := it.ctx.GetAuthorizerList(it.offset, it.pageSize)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.total = list.TotalCount
+	it.page = list.List
+	it.index = 0
+	it.offset += len(list.List)
+	if len(list.List) < it.pageSize || it.offset >= it.total {
+		it.done = true
+	}
+	return len(it.page) > 0
+}
+
+// Authorizer 当前授权方
+func (it *AuthorizerIterator) Authorizer() AuthorizerListItem {
+	return it.page[it.index]
+}
+
+// Total 授权方总数，调用 Next 后有效
+func (it *AuthorizerIterator) Total() int {
+	return it.total
+}
+
+// Err 遍历过程中的错误
+func (it *AuthorizerIterator) Err() error {
+	return it.err
+}
+
+// GetAuthorizerOption 获取授权方选项信息
+func (ctx *Context) GetAuthorizerOption(authorizerAppID string, option AuthorizerOption) (string, error) {
+	req := map[string]string{
+		"component_appid":  ctx.AppID,
+		"authorizer_appid": authorizerAppID,
+		"option_name":      string(option),
+	}
+	var ret struct {
+		util.CommonError
+		OptionValue string `json:"option_value"`
+	}
+	if err := ctx.postComponent(getComponentConfigURL, req, &ret); err != nil {
+		return "", err
+	}
+	if ret.ErrCode != 0 {
+		return "", fmt.Errorf("GetAuthorizerOption error : errcode=%v , errmsg=%v", ret.ErrCode, ret.ErrMsg)
+	}
+	return ret.OptionValue, nil
+}
+
+// SetAuthorizerOption 设置授权方选项信息
+func (ctx *Context) SetAuthorizerOption(authorizerAppID string, option AuthorizerOption, value string) error {
+	req := map[string]string{
+		"component_appid":  ctx.AppID,
+		"authorizer_appid": authorizerAppID,
+		"option_name":      string(option),
+		"option_value":     value,
+	}
+	var ret util.CommonError
+	if err := ctx.postComponent(setComponentConfigURL, req, &ret); err != nil {
+		return err
+	}
+	if ret.ErrCode != 0 {
+		return fmt.Errorf("SetAuthorizerOption error : errcode=%v , errmsg=%v", ret.ErrCode, ret.ErrMsg)
+	}
+	return nil
+}
+
+// postComponent 使用 component_access_token 调用第三方平台接口
+func (ctx *Context) postComponent(urlFormat string, req interface{}, ret interface{}) error {
+	cat, err := ctx.GetComponentAccessToken()
+	if err != nil {
+		return err
+	}
+	body, err := util.PostJSON(fmt.Sprintf(urlFormat, cat), req)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, ret)
+}
@@ -0,0 +1,122 @@
+package context
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// stubAuthorizerList 模拟分页接口，共 total 个授权方，最多返回 available 个，第 failPage 页返回错误
+func stubAuthorizerList(t *testing.T, total, available, failPage int) *[]int {
+	offsets := &[]int{}
+	stubHTTP(t, func(path string, body []byte) string {
+		if path != "/cgi-bin/component/api_get_authorizer_list" {
+			t.Errorf("unexpected request %s", path)
+		}
+		var req struct {
+			Offset int `json:"offset"`
+			Count  int `json:"count"`
+		}
+		json.Unmarshal(body, &req)
+		*offsets = append(*offsets, req.Offset)
+		if len(*offsets) == failPage {
+			return `{"errcode":61003,"errmsg":"component is not authorized by this account"}`
+		}
+		var items []string
+		for i := req.Offset; i < req.Offset+req.Count && i < available; i++ {
+			items = append(items, fmt.Sprintf(`{"authorizer_appid":"wx%d","refresh_token":"r%d","auth_time":%d}`, i, i, i))
+		}
+		return fmt.Sprintf(`{"total_count":%d,"list":[%s]}`, total, strings.Join(items, ","))
+	})
+	return offsets
+}
+
+func collectAuthorizers(it *AuthorizerIterator) []string {
+	var appids []string
+	for it.Next() {
+		appids = append(appids, it.Authorizer().AuthorizerAppID)
+	}
+	return appids
+}
+
+func TestAuthorizerIterator(t *testing.T) {
+	cases := []struct {
+		name                       string
+		total, available, failPage int
+		wantCount                  int
+		wantOffsets                string
+		wantErr                    bool
+	}{
+		{name: "last page short", total: 7, available: 7, wantCount: 7, wantOffsets: "[0 3 6]"},
+		{name: "stop at total count", total: 6, available: 6, wantCount: 6, wantOffsets: "[0 3]"},
+		{name: "fewer than total", total: 10, available: 4, wantCount: 4, wantOffsets: "[0 3]"},
+		{name: "empty", total: 0, available: 0, wantCount: 0, wantOffsets: "[0]"},
+		{name: "error on second page", total: 7, available: 7, failPage: 2, wantCount: 3, wantOffsets: "[0 3]", wantErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			offsets := stubAuthorizerList(t, c.total, c.available, c.failPage)
+			it := newTestComponentContext().NewAuthorizerIterator(3)
+			appids := collectAuthorizers(it)
+			if len(appids) != c.wantCount {
+				t.Errorf("got %d authorizers %v, want %d", len(appids), appids, c.wantCount)
+			}
+			for i, appid := range appids {
+				if appid != fmt.Sprintf("wx%d", i) {
+					t.Errorf("authorizer %d = %s", i, appid)
+				}
+			}
+			if got := fmt.Sprint(*offsets); got != c.wantOffsets {
+				t.Errorf("offsets = %s, want %s", got, c.wantOffsets)
+			}
+			if (it.Err() != nil) != c.wantErr {
+				t.Errorf("Err = %v", it.Err())
+			}
+			if !c.wantErr && it.Total() != c.total {
+				t.Errorf("Total = %d, want %d", it.Total(), c.total)
+			}
+			// 结束后再调用 Next 不会再请求
+			if it.Next() || len(*offsets) != len(strings.Fields(c.wantOffsets)) {
+				t.Error("Next after end should return false without request")
+			}
+		})
+	}
+}
+
+func TestAuthorizerOption(t *testing.T) {
+	ctx := newTestComponentContext()
+	var gotPath string
+	var gotReq map[string]string
+	resp := `{"errcode":0,"errmsg":"ok","option_value":"1"}`
+	stubHTTP(t, func(path string, body []byte) string {
+		gotPath = path
+		gotReq = nil
+		json.Unmarshal(body, &gotReq)
+		return resp
+	})
+
+	value, err := ctx.GetAuthorizerOption("wx1", OptionVoiceRecognize)
+	if err != nil || value != "1" {
+		t.Errorf("GetAuthorizerOption = %s, %v", value, err)
+	}
+	if gotPath != "/cgi-bin/component/api_get_authorizer_option" || gotReq["authorizer_appid"] != "wx1" ||
+		gotReq["option_name"] != "voice_recognize" || gotReq["component_appid"] != "component" {
+		t.Errorf("get request %s %v", gotPath, gotReq)
+	}
+
+	if err = ctx.SetAuthorizerOption("wx1", OptionLocationReport, "2"); err != nil {
+		t.Error(err)
+	}
+	if gotPath != "/cgi-bin/component/api_set_authorizer_option" || gotReq["option_name"] != "location_report" || gotReq["option_value"] != "2" {
+		t.Errorf("set request %s %v", gotPath, gotReq)
+	}
+
+	resp = `{"errcode":61007,"errmsg":"api is unauthorized to component"}`
+	if _, err = ctx.GetAuthorizerOption("wx1", OptionCustomerService); err == nil || !strings.Contains(err.Error(), "61007") {
+		t.Errorf("GetAuthorizerOption err = %v", err)
+	}
+	if err = ctx.SetAuthorizerOption("wx1", OptionCustomerService, "0"); err == nil || !strings.Contains(err.Error(), "61007") {
+		t.Errorf("SetAuthorizerOption err = %v", err)
+	}
+}
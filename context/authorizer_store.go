@@ -0,0 +1,201 @@
+package context
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/cache"
+)
+
+// ErrAuthorizerNotFound 授权方不存在或已取消授权
+var ErrAuthorizerNotFound = errors.New("authorizer not found")
+
+// Authorizer 第三方平台的授权方（公众号或小程序）
+type Authorizer struct {
+	AppID        string    `json:"appid"`
+	RefreshToken string    `json:"refresh_token"`
+	FuncInfo     []int     `json:"func_info,omitempty"` // 授权的权限集 id
+	AuthorizedAt time.Time `json:"authorized_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// AuthorizerStore 授权方存储，用于保存 authorizer_refresh_token
+type AuthorizerStore interface {
+	// SaveAuthorizer 新增或更新授权方
+	SaveAuthorizer(authorizer *Authorizer) error
+	// GetAuthorizer 获取授权方，不存在时返回 ErrAuthorizerNotFound
+	GetAuthorizer(appid string) (*Authorizer, error)
+	// DeleteAuthorizer 删除授权方，不存在时不返回错误
+	DeleteAuthorizer(appid string) error
+}
+
+// CacheAuthorizerStore 基于 cache.Cache 的授权方存储，数据永不过期
+// 使用 memory 缓存时重启会丢失授权方，生产环境请使用 redis 等共享缓存或 SQLAuthorizerStore
+type CacheAuthorizerStore struct {
+	cache cache.Cache
+	keys  cache.KeyBuilder
+}
+
+// NewCacheAuthorizerStore 创建基于缓存的授权方存储，namespace 与 Config.CacheNamespace 一致
+func NewCacheAuthorizerStore(c cache.Cache, namespace string) *CacheAuthorizerStore {
+	return &CacheAuthorizerStore{cache: c, keys: cache.NewKeyBuilder(namespace)}
+}
+
+// SaveAuthorizer 新增或更新授权方
+func (s *CacheAuthorizerStore) SaveAuthorizer(authorizer *Authorizer) error {
+	data, err := json.Marshal(authorizer)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(s.keys.Authorizer(authorizer.AppID), string(data), 0)
+}
+
+// GetAuthorizer 获取授权方
+func (s *CacheAuthorizerStore) GetAuthorizer(appid string) (*Authorizer, error) {
+	data, found, err := cache.GetString(stdcontext.Background(), cache.WithContext(s.cache), s.keys.Authorizer(appid))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrAuthorizerNotFound
+	}
+	authorizer := new(Authorizer)
+	if err = json.Unmarshal([]byte(data), authorizer); err != nil {
+		return nil, fmt.Errorf("decode authorizer %s error, err=%v", appid, err)
+	}
+	return authorizer, nil
+}
+
+// DeleteAuthorizer 删除授权方
+func (s *CacheAuthorizerStore) DeleteAuthorizer(appid string) error {
+	return s.cache.Delete(s.keys.Authorizer(appid))
+}
+
+// HandleAuthorizationEvent 处理授权事件：授权成功、更新授权时保存授权方，取消授权时删除
+// infoType 为 authorized、updateauthorized 或 unauthorized，其它类型忽略
+func (ctx *Context) HandleAuthorizationEvent(infoType, authorizerAppID, authorizationCode string) error {
+	if ctx.AuthorizerStore == nil {
+		return fmt.Errorf("authorizer store is not set")
+	}
+	switch infoType {
+	case "authorized", "updateauthorized":
+		_, err := ctx.SaveAuthorizationCode(authorizationCode)
+		return err
+	case "unauthorized":
+		if err := ctx.AuthorizerStore.DeleteAuthorizer(authorizerAppID); err != nil {
+			return err
+		}
+		return ctx.Cache.Delete(ctx.CacheKeys().AuthorizerAccessToken(authorizerAppID))
+	}
+	return nil
+}
+
+// SaveAuthorizationCode 使用授权码换取授权信息并保存到 AuthorizerStore，同时缓存 authorizer_access_token
+// 可在授权回调页中调用，也会在收到授权事件推送时自动调用
+func (ctx *Context) SaveAuthorizationCode(authorizationCode string) (*Authorizer, error) {
+	if ctx.AuthorizerStore == nil {
+		return nil, fmt.Errorf("authorizer store is not set")
+	}
+	info, err := ctx.QueryAuthCode(authorizationCode)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil || info.Appid == "" || info.RefreshToken == "" {
+		return nil, fmt.Errorf("query auth code %s returns empty authorization info", authorizationCode)
+	}
+	now := time.Now()
+	authorizer := &Authorizer{
+		AppID:        info.Appid,
+		RefreshToken: info.RefreshToken,
+		AuthorizedAt: now,
+		UpdatedAt:    now,
+	}
+	for _, f := range info.FuncInfo {
+		authorizer.FuncInfo = append(authorizer.FuncInfo, f.FuncscopeCategory.ID)
+	}
+	if old, err := ctx.AuthorizerStore.GetAuthorizer(info.Appid); err == nil {
+		authorizer.AuthorizedAt = old.AuthorizedAt
+	}
+	if err = ctx.AuthorizerStore.SaveAuthorizer(authorizer); err != nil {
+		return nil, err
+	}
+	if ttl := authorizerTokenTTL(info.ExpiresIn); info.AccessToken != "" && ttl > 0 {
+		err = ctx.Cache.Set(ctx.CacheKeys().AuthorizerAccessToken(info.Appid), info.AccessToken, ttl)
+	}
+	return authorizer, err
+}
+
+// authorizerTokenTTL 授权方 access_token 的缓存时间，提前 60 秒过期
+// 未返回 expires_in 或有效期过短时返回 0，此时不缓存，避免负数时长在部分缓存中表示永不过期
+func authorizerTokenTTL(expiresIn int64) time.Duration {
+	if expiresIn <= 60 {
+		return 0
+	}
+	return time.Duration(expiresIn-60) * time.Second
+}
+
+// GetAuthorizerAccessToken 获取授权方 access_token，缓存失效时使用 AuthorizerStore 中的 refresh_token 刷新
+// 同一个 appid 的刷新串行执行，等待的调用直接使用刷新后缓存的 access_token
+func (ctx *Context) GetAuthorizerAccessToken(appid string) (string, error) {
+	key := ctx.CacheKeys().AuthorizerAccessToken(appid)
+	accessToken, found, err := ctx.getCacheString(key)
+	if err != nil || found {
+		return accessToken, err
+	}
+	if ctx.AuthorizerStore == nil {
+		return "", fmt.Errorf("authorizer store is not set")
+	}
+	lock, _ := ctx.authorizerLocks.LoadOrStore(appid, new(sync.Mutex))
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+	accessToken, found, err = ctx.getCacheString(key)
+	if err != nil || found {
+		return accessToken, err
+	}
+	authorizer, err := ctx.AuthorizerStore.GetAuthorizer(appid)
+	if err != nil {
+		return "", err
+	}
+	ret, err := ctx.RefreshAuthrToken(appid, authorizer.RefreshToken)
+	if err != nil {
+		return "", err
+	}
+	// refresh_token 发生变化时更新保存
+	if ret.RefreshToken != "" && ret.RefreshToken != authorizer.RefreshToken {
+		authorizer.RefreshToken = ret.RefreshToken
+		authorizer.UpdatedAt = time.Now()
+		if err = ctx.AuthorizerStore.SaveAuthorizer(authorizer); err != nil {
+			return "", err
+		}
+	}
+	return ret.AccessToken, nil
+}
+
+// InvalidateAuthorizerAccessToken 删除缓存的授权方 access_token，下次获取时使用 refresh_token 刷新
+// 使用 cache.Layered 时同时通知其它实例删除本地副本
+func (ctx *Context) InvalidateAuthorizerAccessToken(appid string) error {
+	return ctx.Cache.Delete(ctx.CacheKeys().AuthorizerAccessToken(appid))
+}
+
+// NewAuthorizerContext 创建代授权方调用接口的 Context，access_token 通过 GetAuthorizerAccessToken 获取
+// 可用于创建公众号的菜单、用户、素材等接口实例
+func (ctx *Context) NewAuthorizerContext(appid string) *Context {
+	authorizerCtx := &Context{
+		AppID:           appid,
+		Token:           ctx.Token,
+		EncodingAESKey:  ctx.EncodingAESKey,
+		Cache:           ctx.Cache,
+		CacheNamespace:  ctx.CacheNamespace,
+		AuthorizerStore: ctx.AuthorizerStore,
+	}
+	authorizerCtx.SetAccessTokenLock(new(sync.RWMutex))
+	authorizerCtx.SetJsAPITicketLock(new(sync.RWMutex))
+	authorizerCtx.SetGetAccessTokenFunc(func(*Context) (string, error) {
+		return ctx.GetAuthorizerAccessToken(appid)
+	})
+	return authorizerCtx
+}
@@ -0,0 +1,117 @@
+package context
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLAuthorizerStore 基于 database/sql 的授权方存储，只使用通用 SQL，兼容 MySQL、PostgreSQL、SQLite 等
+// 表结构参考：
+//
+//	CREATE TABLE wechat_authorizer (
+//		appid         VARCHAR(64)  NOT NULL PRIMARY KEY,
+//		refresh_token VARCHAR(512) NOT NULL,
+//		func_info     VARCHAR(512) NOT NULL DEFAULT '',
+//		authorized_at BIGINT       NOT NULL,
+//		updated_at    BIGINT       NOT NULL
+//	);
+type SQLAuthorizerStore struct {
+	db    *sql.DB
+	table string
+	// dollar 使用 $1 形式的占位符（PostgreSQL），否则使用 ?
+	dollar bool
+}
+
+// NewSQLAuthorizerStore 创建基于数据库的授权方存储，table 为空时使用 wechat_authorizer
+// PostgreSQL 等使用 $1 占位符的数据库 dollarPlaceholder 传 true
+func NewSQLAuthorizerStore(db *sql.DB, table string, dollarPlaceholder bool) *SQLAuthorizerStore {
+	if table == "" {
+		table = "wechat_authorizer"
+	}
+	return &SQLAuthorizerStore{db: db, table: table, dollar: dollarPlaceholder}
+}
+
+// SaveAuthorizer 新增或更新授权方，先更新，不存在时再插入，以兼容不同数据库的 upsert 语法
+// 并发保存同一授权方（如同时收到多次 updateauthorized 推送）时，插入冲突的一方改为更新
+func (s *SQLAuthorizerStore) SaveAuthorizer(authorizer *Authorizer) error {
+	funcInfo := make([]string, 0, len(authorizer.FuncInfo))
+	for _, id := range authorizer.FuncInfo {
+		funcInfo = append(funcInfo, strconv.Itoa(id))
+	}
+	update := func() (int64, error) {
+		res, err := s.db.Exec(s.query("UPDATE %s SET refresh_token = ?, func_info = ?, authorized_at = ?, updated_at = ? WHERE appid = ?"),
+			authorizer.RefreshToken, strings.Join(funcInfo, ","), authorizer.AuthorizedAt.Unix(), authorizer.UpdatedAt.Unix(), authorizer.AppID)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	}
+	if n, err := update(); err != nil || n > 0 {
+		return err
+	}
+	_, insertErr := s.db.Exec(s.query("INSERT INTO %s (appid, refresh_token, func_info, authorized_at, updated_at) VALUES (?, ?, ?, ?, ?)"),
+		authorizer.AppID, authorizer.RefreshToken, strings.Join(funcInfo, ","),
+		authorizer.AuthorizedAt.Unix(), authorizer.UpdatedAt.Unix())
+	if insertErr == nil {
+		return nil
+	}
+	// 插入失败时可能是其它请求已插入，或 MySQL 数据未变化时 UPDATE 影响行数为 0，记录存在则再更新一次
+	if _, err := s.GetAuthorizer(authorizer.AppID); err != nil {
+		return insertErr
+	}
+	_, err := update()
+	return err
+}
+
+// GetAuthorizer 获取授权方
+func (s *SQLAuthorizerStore) GetAuthorizer(appid string) (*Authorizer, error) {
+	var (
+		funcInfo                string
+		authorizedAt, updatedAt int64
+	)
+	authorizer := &Authorizer{}
+	err := s.db.QueryRow(s.query("SELECT appid, refresh_token, func_info, authorized_at, updated_at FROM %s WHERE appid = ?"), appid).
+		Scan(&authorizer.AppID, &authorizer.RefreshToken, &funcInfo, &authorizedAt, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrAuthorizerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range strings.Split(funcInfo, ",") {
+		if id, err := strconv.Atoi(v); err == nil {
+			authorizer.FuncInfo = append(authorizer.FuncInfo, id)
+		}
+	}
+	authorizer.AuthorizedAt = time.Unix(authorizedAt, 0)
+	authorizer.UpdatedAt = time.Unix(updatedAt, 0)
+	return authorizer, nil
+}
+
+// DeleteAuthorizer 删除授权方
+func (s *SQLAuthorizerStore) DeleteAuthorizer(appid string) error {
+	_, err := s.db.Exec(s.query("DELETE FROM %s WHERE appid = ?"), appid)
+	return err
+}
+
+// query 填充表名并按数据库转换占位符
+func (s *SQLAuthorizerStore) query(format string) string {
+	q := fmt.Sprintf(format, s.table)
+	if !s.dollar {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, c := range q {
+		if c == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
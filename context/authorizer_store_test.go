@@ -0,0 +1,219 @@
+package context
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/pengshang1995/wechat-sdk/cache"
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+func TestCacheAuthorizerStore(t *testing.T) {
+	mem := cache.NewMemory()
+	defer mem.Close()
+	store := NewCacheAuthorizerStore(mem, "prod")
+
+	if _, err := store.GetAuthorizer("wx1"); err != ErrAuthorizerNotFound {
+		t.Errorf("expect ErrAuthorizerNotFound, got %v", err)
+	}
+	now := time.Unix(time.Now().Unix(), 0)
+	if err := store.SaveAuthorizer(&Authorizer{AppID: "wx1", RefreshToken: "refresh", FuncInfo: []int{1, 17}, AuthorizedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	a, err := store.GetAuthorizer("wx1")
+	if err != nil || a.RefreshToken != "refresh" || len(a.FuncInfo) != 2 || !a.AuthorizedAt.Equal(now) {
+		t.Errorf("GetAuthorizer = %+v, %v", a, err)
+	}
+	if err = store.DeleteAuthorizer("wx1"); err != nil {
+		t.Error(err)
+	}
+	if _, err = store.GetAuthorizer("wx1"); err != ErrAuthorizerNotFound {
+		t.Errorf("expect ErrAuthorizerNotFound after delete, got %v", err)
+	}
+}
+
+func TestSQLAuthorizerStoreQuery(t *testing.T) {
+	s := NewSQLAuthorizerStore(nil, "", true)
+	if q := s.query("DELETE FROM %s WHERE appid = ? AND x = ?"); q != "DELETE FROM wechat_authorizer WHERE appid = $1 AND x = $2" {
+		t.Errorf("query = %s", q)
+	}
+	s = NewSQLAuthorizerStore(nil, "authorizer", false)
+	if q := s.query("DELETE FROM %s WHERE appid = ?"); q != "DELETE FROM authorizer WHERE appid = ?" {
+		t.Errorf("query = %s", q)
+	}
+}
+
+func TestSQLAuthorizerStore(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	// 内存数据库每个连接独立，只使用一个连接
+	db.SetMaxOpenConns(1)
+	if _, err = db.Exec(`CREATE TABLE wechat_authorizer (
+		appid         VARCHAR(64)  NOT NULL PRIMARY KEY,
+		refresh_token VARCHAR(512) NOT NULL,
+		func_info     VARCHAR(512) NOT NULL DEFAULT '',
+		authorized_at BIGINT       NOT NULL,
+		updated_at    BIGINT       NOT NULL
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	store := NewSQLAuthorizerStore(db, "", false)
+
+	if _, err = store.GetAuthorizer("wx1"); err != ErrAuthorizerNotFound {
+		t.Errorf("expect ErrAuthorizerNotFound, got %v", err)
+	}
+	now := time.Unix(time.Now().Unix(), 0)
+	if err = store.SaveAuthorizer(&Authorizer{AppID: "wx1", RefreshToken: "refresh1", FuncInfo: []int{1, 17}, AuthorizedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatal(err)
+	}
+	// 重复保存相同数据及并发更新都不报错
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := store.SaveAuthorizer(&Authorizer{AppID: "wx1", RefreshToken: "refresh2", AuthorizedAt: now, UpdatedAt: now.Add(time.Second)}); err != nil {
+				t.Errorf("save %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	a, err := store.GetAuthorizer("wx1")
+	if err != nil || a.RefreshToken != "refresh2" || len(a.FuncInfo) != 0 || !a.AuthorizedAt.Equal(now) || !a.UpdatedAt.Equal(now.Add(time.Second)) {
+		t.Errorf("GetAuthorizer = %+v, %v", a, err)
+	}
+	var count int
+	if err = db.QueryRow("SELECT COUNT(*) FROM wechat_authorizer").Scan(&count); err != nil || count != 1 {
+		t.Errorf("rows = %d, err = %v", count, err)
+	}
+	if err = store.DeleteAuthorizer("wx1"); err != nil {
+		t.Error(err)
+	}
+	if _, err = store.GetAuthorizer("wx1"); err != ErrAuthorizerNotFound {
+		t.Errorf("expect ErrAuthorizerNotFound after delete, got %v", err)
+	}
+	if err = store.DeleteAuthorizer("wx1"); err != nil {
+		t.Errorf("delete missing authorizer: %v", err)
+	}
+}
+
+// stubHTTP 将接口请求转给 handler，返回值作为响应内容
+func stubHTTP(t *testing.T, handler func(path string, body []byte) string) {
+	t.Helper()
+	old := util.HTTPTransport
+	util.HTTPTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var body []byte
+		if r.Body != nil {
+			body, _ = ioutil.ReadAll(r.Body)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(handler(r.URL.Path, body))),
+			Request:    r,
+		}, nil
+	})
+	t.Cleanup(func() { util.HTTPTransport = old })
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func newTestComponentContext() *Context {
+	mem := cache.NewMemory()
+	ctx := &Context{AppID: "component", Cache: mem, AuthorizerStore: NewCacheAuthorizerStore(mem, "")}
+	mem.Set(ctx.CacheKeys().ComponentAccessToken(ctx.AppID), "component_token", time.Hour)
+	return ctx
+}
+
+func TestHandleAuthorizationEvent(t *testing.T) {
+	ctx := newTestComponentContext()
+	refreshToken, expiresIn := "refresh1", 7200
+	stubHTTP(t, func(path string, body []byte) string {
+		if path != "/cgi-bin/component/api_query_auth" {
+			t.Errorf("unexpected request %s", path)
+		}
+		return fmt.Sprintf(`{"authorization_info":{"authorizer_appid":"wx1","authorizer_access_token":"token1","expires_in":%d,`+
+			`"authorizer_refresh_token":"%s","func_info":[{"funcscope_category":{"id":17}}]}}`, expiresIn, refreshToken)
+	})
+
+	if err := ctx.HandleAuthorizationEvent("authorized", "wx1", "code1"); err != nil {
+		t.Fatal(err)
+	}
+	a, err := ctx.AuthorizerStore.GetAuthorizer("wx1")
+	if err != nil || a.RefreshToken != "refresh1" || len(a.FuncInfo) != 1 || a.FuncInfo[0] != 17 {
+		t.Fatalf("authorizer = %+v, %v", a, err)
+	}
+	if token, _ := ctx.GetAuthrAccessToken("wx1"); token != "token1" {
+		t.Errorf("cached token = %s", token)
+	}
+
+	// 更新授权时保留首次授权时间；未返回 expires_in 时不缓存 access_token
+	ctx.Cache.Delete(ctx.CacheKeys().AuthorizerAccessToken("wx1"))
+	refreshToken, expiresIn = "refresh2", 0
+	if err = ctx.HandleAuthorizationEvent("updateauthorized", "wx1", "code2"); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := ctx.AuthorizerStore.GetAuthorizer("wx1")
+	if err != nil || updated.RefreshToken != "refresh2" || !updated.AuthorizedAt.Equal(a.AuthorizedAt) {
+		t.Fatalf("updated authorizer = %+v, %v", updated, err)
+	}
+	if ctx.Cache.IsExist(ctx.CacheKeys().AuthorizerAccessToken("wx1")) {
+		t.Error("token without expires_in should not be cached")
+	}
+
+	if err = ctx.HandleAuthorizationEvent("unauthorized", "wx1", ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = ctx.AuthorizerStore.GetAuthorizer("wx1"); err != ErrAuthorizerNotFound {
+		t.Errorf("expect ErrAuthorizerNotFound after unauthorized, got %v", err)
+	}
+}
+
+func TestGetAuthorizerAccessTokenSerialized(t *testing.T) {
+	ctx := newTestComponentContext()
+	now := time.Now()
+	ctx.AuthorizerStore.SaveAuthorizer(&Authorizer{AppID: "wx1", RefreshToken: "refresh1", AuthorizedAt: now, UpdatedAt: now})
+	var refreshes int32
+	stubHTTP(t, func(path string, body []byte) string {
+		if !strings.Contains(string(body), `"authorizer_refresh_token":"refresh1"`) {
+			t.Errorf("refresh with unexpected body %s", body)
+		}
+		atomic.AddInt32(&refreshes, 1)
+		time.Sleep(20 * time.Millisecond)
+		return `{"authorizer_access_token":"token2","expires_in":7200,"authorizer_refresh_token":"refresh2"}`
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := ctx.GetAuthorizerAccessToken("wx1"); err != nil || token != "token2" {
+				t.Errorf("GetAuthorizerAccessToken = %s, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if refreshes != 1 {
+		t.Errorf("refreshes = %d, want 1", refreshes)
+	}
+	if a, _ := ctx.AuthorizerStore.GetAuthorizer("wx1"); a.RefreshToken != "refresh2" {
+		t.Errorf("refresh token should be updated, got %s", a.RefreshToken)
+	}
+}
@@ -3,7 +3,7 @@ package context
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/pengshang1995/wechat-sdk/cache"
+	"strconv"
 	"time"
 
 	"github.com/pengshang1995/wechat-sdk/util"
@@ -27,31 +27,67 @@ type ComponentAccessToken struct {
 
 // SetComponentVerifyTicket 保存每10min一次的微信令牌
 func (ctx *Context) SetComponentVerifyTicket(ticket string) {
-	err := ctx.Cache.Set(fmt.Sprintf(cache.ComponentVerifyTicket, ctx.AppID), ticket, 0)
-	if err != nil {
+	if err := ctx.SaveComponentVerifyTicket(ticket); err != nil {
 		fmt.Printf("保存票据报错: %v\n", err)
 	}
 }
 
+// SaveComponentVerifyTicket 保存票据及接收时间，票据永不过期，以最后一次推送为准
+func (ctx *Context) SaveComponentVerifyTicket(ticket string) error {
+	if ticket == "" {
+		return fmt.Errorf("component verify ticket is empty")
+	}
+	keys := ctx.CacheKeys()
+	if err := ctx.Cache.Set(keys.ComponentVerifyTicket(ctx.AppID), ticket, 0); err != nil {
+		return err
+	}
+	return ctx.Cache.Set(keys.ComponentVerifyTicketTime(ctx.AppID), strconv.FormatInt(time.Now().Unix(), 10), 0)
+}
+
+// GetComponentVerifyTicketTime 获取最后一次收到票据推送的时间
+// 微信每 10 分钟推送一次，可据此监控推送是否中断
+func (ctx *Context) GetComponentVerifyTicketTime() (time.Time, error) {
+	val, found, err := ctx.getCacheString(ctx.CacheKeys().ComponentVerifyTicketTime(ctx.AppID))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !found {
+		return time.Time{}, fmt.Errorf("component verify ticket has never been received")
+	}
+	sec, err := strconv.ParseInt(val, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid component verify ticket time %q", val)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// GetComponentVerifyTicketAge 距最后一次收到票据推送的时长
+func (ctx *Context) GetComponentVerifyTicketAge() (time.Duration, error) {
+	t, err := ctx.GetComponentVerifyTicketTime()
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(t), nil
+}
+
 // GetComponentVerifyTicket 获取票据
 func (ctx *Context) GetComponentVerifyTicket() (string, error) {
-	val := ctx.Cache.Get(fmt.Sprintf(cache.ComponentVerifyTicket, ctx.AppID))
-	if val == nil {
-		return "", fmt.Errorf("cann't get component verify ticket")
+	val, found, err := ctx.getCacheString(ctx.CacheKeys().ComponentVerifyTicket(ctx.AppID))
+	if err != nil {
+		return "", err
 	}
-	if v, ok := val.(string); ok {
-		return v, nil
+	if !found || val == "" {
+		return "", fmt.Errorf("cann't get component verify ticket")
 	}
-	return "", fmt.Errorf("cann't get component verify ticket")
+	return val, nil
 }
 
 // GetComponentAccessToken 获取 ComponentAccessToken
 func (ctx *Context) GetComponentAccessToken() (string, error) {
-	accessTokenCacheKey := fmt.Sprintf(cache.ComponentAccessToken, ctx.AppID)
-	var result string
-	t := ctx.Cache.Get(accessTokenCacheKey)
-	if v, ok := t.(string); ok {
-		result = v
+	accessTokenCacheKey := ctx.CacheKeys().ComponentAccessToken(ctx.AppID)
+	result, _, err := ctx.getCacheString(accessTokenCacheKey)
+	if err != nil {
+		return "", err
 	}
 	if result == "" {
 		t, err := ctx.GetComponentVerifyTicket()
@@ -89,7 +125,7 @@ func (ctx *Context) SetComponentAccessToken(verifyTicket string) (*ComponentAcce
 	if at.ErrCode != 0 {
 		return nil, fmt.Errorf("Componet access token err  [%d]: %s", at.ErrCode, at.ErrMsg)
 	}
-	accessTokenCacheKey := fmt.Sprintf(cache.ComponentAccessToken, ctx.AppID)
+	accessTokenCacheKey := ctx.CacheKeys().ComponentAccessToken(ctx.AppID)
 	expires := at.ExpiresIn - 1500
 	err = ctx.Cache.Set(accessTokenCacheKey, at.AccessToken, time.Duration(expires)*time.Second)
 	if err != nil {
@@ -198,26 +234,39 @@ func (ctx *Context) RefreshAuthrToken(appid, refreshToken string) (*AuthrAccessT
 		return nil, err
 	}
 
-	ret := &AuthrAccessToken{}
-	if err := json.Unmarshal(body, ret); err != nil {
+	var result struct {
+		util.CommonError
+		AuthrAccessToken
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
+	if result.ErrCode != 0 {
+		return nil, fmt.Errorf("refresh authorizer %s token error : errcode=%v , errormsg=%v", appid, result.ErrCode, result.ErrMsg)
+	}
+	ret := &result.AuthrAccessToken
+	ret.Appid = appid
 
-	authrTokenKey := "authorizer_access_token_" + appid
+	authrTokenKey := ctx.CacheKeys().AuthorizerAccessToken(appid)
 
-	ctx.Cache.Set(authrTokenKey, ret.AccessToken, time.Duration(ret.ExpiresIn-60)*time.Second)
+	if ttl := authorizerTokenTTL(ret.ExpiresIn); ttl > 0 {
+		ctx.Cache.Set(authrTokenKey, ret.AccessToken, ttl)
+	}
 
 	return ret, nil
 }
 
 // GetAuthrAccessToken 获取授权方AccessToken
 func (ctx *Context) GetAuthrAccessToken(appid string) (string, error) {
-	authrTokenKey := "authorizer_access_token_" + appid
-	val := ctx.Cache.Get(authrTokenKey)
-	if val == nil {
+	authrTokenKey := ctx.CacheKeys().AuthorizerAccessToken(appid)
+	val, found, err := ctx.getCacheString(authrTokenKey)
+	if err != nil {
+		return "", err
+	}
+	if !found {
 		return "", fmt.Errorf("cannot get authorizer %s access token", appid)
 	}
-	return val.(string), nil
+	return val, nil
 }
 
 // AuthorizerInfo 授权方详细信息
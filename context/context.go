@@ -1,6 +1,7 @@
 package context
 
 import (
+	stdcontext "context"
 	"net/http"
 	"sync"
 
@@ -17,9 +18,16 @@ type Context struct {
 	PayNotifyURL   string
 	PayKey         string
 	P12            []byte
+	PayCertPEM     []byte // PEM 格式的商户证书，与 PayKeyPEM 一起使用，优先于 P12
+	PayKeyPEM      []byte // PEM 格式的商户证书私钥
+	PaySandbox     bool   // 支付仿真测试模式
+	CacheNamespace string // 缓存 key 的命名空间，多个环境共用一个缓存时用于隔离
 
 	Cache cache.Cache
 
+	// AuthorizerStore 第三方平台授权方存储
+	AuthorizerStore AuthorizerStore
+
 	Writer  http.ResponseWriter
 	Request *http.Request
 
@@ -29,8 +37,17 @@ type Context struct {
 	// jsAPITicket 读写锁 同一个AppID一个
 	jsAPITicketLock *sync.RWMutex
 
+	// authorizerLocks 刷新授权方 access_token 的锁，同一个 appid 一个，避免并发刷新使 refresh_token 失效
+	authorizerLocks sync.Map
+
 	// accessTokenFunc 自定义获取 access token 的方法
 	accessTokenFunc GetAccessTokenFunc
+
+	// payTLSClient 携带商户证书的 http 客户端，加载一次后复用
+	payTLSClient *http.Client
+	// p12TLSClients 单次调用指定的 P12 证书对应的 http 客户端，按证书指纹缓存
+	p12TLSClients map[string]*http.Client
+	payCertLock   sync.Mutex
 }
 
 // Query returns the keyed url query value if it exists
@@ -57,3 +74,13 @@ func (ctx *Context) SetJsAPITicketLock(lock *sync.RWMutex) {
 func (ctx *Context) GetJsAPITicketLock() *sync.RWMutex {
 	return ctx.jsAPITicketLock
 }
+
+// CacheKeys 返回当前命名空间下的缓存 key 构造器
+func (ctx *Context) CacheKeys() cache.KeyBuilder {
+	return cache.NewKeyBuilder(ctx.CacheNamespace)
+}
+
+// getCacheString 从缓存中读取字符串，缓存故障时返回 error，避免被当作未命中而频繁刷新 token
+func (ctx *Context) getCacheString(key string) (string, bool, error) {
+	return cache.GetString(stdcontext.Background(), cache.WithContext(ctx.Cache), key)
+}
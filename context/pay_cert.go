@@ -0,0 +1,90 @@
+package context
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+// maxP12TLSClients 按证书指纹缓存的 http 客户端数量上限，超出时淘汰任意一个
+const maxP12TLSClients = 16
+
+// HasPayCert 是否配置了商户证书
+func (ctx *Context) HasPayCert() bool {
+	return len(ctx.PayCertPEM) > 0 || len(ctx.PayKeyPEM) > 0 || len(ctx.P12) > 0
+}
+
+// LoadPayCert 加载并校验商户证书，成功后缓存携带证书的 http 客户端
+// PEM 格式的证书和私钥优先，其次使用 P12（密码为商户号）
+func (ctx *Context) LoadPayCert() error {
+	ctx.payCertLock.Lock()
+	defer ctx.payCertLock.Unlock()
+	return ctx.loadPayCert()
+}
+
+// GetPayTLSClient 获取携带商户证书的 http 客户端，未加载时自动加载
+func (ctx *Context) GetPayTLSClient() (*http.Client, error) {
+	ctx.payCertLock.Lock()
+	defer ctx.payCertLock.Unlock()
+	if ctx.payTLSClient != nil {
+		return ctx.payTLSClient, nil
+	}
+	if err := ctx.loadPayCert(); err != nil {
+		return nil, err
+	}
+	return ctx.payTLSClient, nil
+}
+
+// GetP12TLSClient 获取携带指定 P12 证书（密码为商户号）的 http 客户端，按证书指纹缓存复用
+func (ctx *Context) GetP12TLSClient(p12 []byte) (*http.Client, error) {
+	sum := sha256.Sum256(p12)
+	fingerprint := hex.EncodeToString(sum[:])
+	ctx.payCertLock.Lock()
+	defer ctx.payCertLock.Unlock()
+	if client, ok := ctx.p12TLSClients[fingerprint]; ok {
+		return client, nil
+	}
+	cert, err := util.LoadP12Cert(p12, ctx.PayMchID)
+	if err != nil {
+		return nil, err
+	}
+	client := util.NewTLSClient(cert)
+	ctx.cacheP12TLSClient(fingerprint, client)
+	return client, nil
+}
+
+// cacheP12TLSClient 缓存客户端，达到上限时先淘汰一个并关闭其空闲连接，需持有 payCertLock
+func (ctx *Context) cacheP12TLSClient(fingerprint string, client *http.Client) {
+	if ctx.p12TLSClients == nil {
+		ctx.p12TLSClients = make(map[string]*http.Client)
+	}
+	if len(ctx.p12TLSClients) >= maxP12TLSClients {
+		for key, evicted := range ctx.p12TLSClients {
+			delete(ctx.p12TLSClients, key)
+			evicted.CloseIdleConnections()
+			break
+		}
+	}
+	ctx.p12TLSClients[fingerprint] = client
+}
+
+func (ctx *Context) loadPayCert() (err error) {
+	var cert tls.Certificate
+	switch {
+	case len(ctx.PayCertPEM) > 0 || len(ctx.PayKeyPEM) > 0:
+		cert, err = util.LoadPEMCert(ctx.PayCertPEM, ctx.PayKeyPEM)
+	case len(ctx.P12) > 0:
+		cert, err = util.LoadP12Cert(ctx.P12, ctx.PayMchID)
+	default:
+		err = fmt.Errorf("pay cert is not configured")
+	}
+	if err != nil {
+		return
+	}
+	ctx.payTLSClient = util.NewTLSClient(cert)
+	return
+}
@@ -0,0 +1,56 @@
+package context
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestGetP12TLSClient(t *testing.T) {
+	ctx := &Context{PayMchID: "1230000109"}
+	if _, err := ctx.GetP12TLSClient([]byte("not a p12")); err == nil {
+		t.Fatal("expect error for invalid p12")
+	}
+	if len(ctx.p12TLSClients) != 0 {
+		t.Fatal("invalid cert should not be cached")
+	}
+
+	// 相同证书复用同一个客户端
+	p12 := []byte("cached p12")
+	sum := sha256.Sum256(p12)
+	cached := &http.Client{}
+	ctx.p12TLSClients = map[string]*http.Client{hex.EncodeToString(sum[:]): cached}
+	client, err := ctx.GetP12TLSClient(p12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client != cached {
+		t.Fatal("expect cached client for the same cert")
+	}
+}
+
+func TestP12TLSClientsBounded(t *testing.T) {
+	ctx := &Context{}
+	for i := 0; i < maxP12TLSClients+5; i++ {
+		ctx.cacheP12TLSClient(fmt.Sprintf("fingerprint%d", i), &http.Client{})
+	}
+	if len(ctx.p12TLSClients) != maxP12TLSClients {
+		t.Fatalf("len(p12TLSClients) = %d, want %d", len(ctx.p12TLSClients), maxP12TLSClients)
+	}
+	if _, ok := ctx.p12TLSClients[fmt.Sprintf("fingerprint%d", maxP12TLSClients+4)]; !ok {
+		t.Fatal("latest client should be cached")
+	}
+}
+
+func TestLoadPayCert(t *testing.T) {
+	ctx := &Context{}
+	if ctx.HasPayCert() || ctx.LoadPayCert() == nil {
+		t.Fatal("expect error without pay cert")
+	}
+	ctx.PayCertPEM = []byte("not a pem")
+	if !ctx.HasPayCert() || ctx.LoadPayCert() == nil {
+		t.Fatal("expect error for invalid pem")
+	}
+}
@@ -33,10 +33,9 @@ func (ctx *Context) GetQyAccessToken() (accessToken string, err error) {
 	ctx.accessTokenLock.Lock()
 	defer ctx.accessTokenLock.Unlock()
 
-	accessTokenCacheKey := fmt.Sprintf("qy_access_token_%s", ctx.AppID)
-	val := ctx.Cache.Get(accessTokenCacheKey)
-	if val != nil {
-		accessToken = val.(string)
+	accessTokenCacheKey := ctx.CacheKeys().QyAccessToken(ctx.AppID)
+	var found bool
+	if accessToken, found, err = ctx.getCacheString(accessTokenCacheKey); err != nil || found {
 		return
 	}
 
@@ -69,7 +68,7 @@ func (ctx *Context) GetQyAccessTokenFromServer() (resQyAccessToken ResQyAccessTo
 		return
 	}
 
-	qyAccessTokenCacheKey := fmt.Sprintf("qy_access_token_%s", ctx.AppID)
+	qyAccessTokenCacheKey := ctx.CacheKeys().QyAccessToken(ctx.AppID)
 	expires := resQyAccessToken.ExpiresIn - 1500
 	err = ctx.Cache.Set(qyAccessTokenCacheKey, resQyAccessToken.AccessToken, time.Duration(expires)*time.Second)
 	return
@@ -1,10 +1,12 @@
 package js
 
 import (
+	stdcontext "context"
 	"encoding/json"
 	"fmt"
 	"time"
 
+	"github.com/pengshang1995/wechat-sdk/cache"
 	"github.com/pengshang1995/wechat-sdk/context"
 	"github.com/pengshang1995/wechat-sdk/util"
 )
@@ -67,10 +69,10 @@ func (js *Js) GetTicket() (ticketStr string, err error) {
 	defer js.GetJsAPITicketLock().Unlock()
 
 	//先从cache中取
-	jsAPITicketCacheKey := fmt.Sprintf("jsapi_ticket_%s", js.AppID)
-	val := js.Cache.Get(jsAPITicketCacheKey)
-	if val != nil {
-		ticketStr = val.(string)
+	jsAPITicketCacheKey := js.CacheKeys().JsAPITicket(js.AppID)
+	var found bool
+	ticketStr, found, err = cache.GetString(stdcontext.Background(), cache.WithContext(js.Cache), jsAPITicketCacheKey)
+	if err != nil || found {
 		return
 	}
 	var ticket resTicket
@@ -102,7 +104,7 @@ func (js *Js) getTicketFromServer() (ticket resTicket, err error) {
 		return
 	}
 
-	jsAPITicketCacheKey := fmt.Sprintf("jsapi_ticket_%s", js.AppID)
+	jsAPITicketCacheKey := js.CacheKeys().JsAPITicket(js.AppID)
 	expires := ticket.ExpiresIn - 1500
 	err = js.Cache.Set(jsAPITicketCacheKey, ticket.Ticket, time.Duration(expires)*time.Second)
 	return
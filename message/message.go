@@ -191,12 +191,14 @@ type MixMessage struct {
 	MiniProgramAppid             string   `xml:"appid"`
 	MiniProgramStatus            int64    `xml:"status"`
 	MiniProgramMsg               string   `xml:"msg"`
+	MiniProgramAuthCode          string   `xml:"auth_code"`
 	MiniProgramRegInfo           struct {
 		CompanyCode        string `xml:"code"`
 		CompanyName        string `xml:"name"`
 		CodeType           int8   `xml:"code_type"`
 		LegalPersonaWechat string `xml:"legal_persona_wechat"`
 		LegalPersonaName   string `xml:"legal_persona_name"`
+		ComponentPhone     string `xml:"component_phone"`
 	} `xml:"info"`
 	MiniProgramApplyInfo struct {
 		ApiName   string `xml:"api_name"`
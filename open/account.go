@@ -0,0 +1,241 @@
+package open
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+	"github.com/pengshang1995/wechat-sdk/material"
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+const (
+	setNicknameURL         = "https://api.weixin.qq.com/wxa/setnickname"
+	checkNicknameURL       = "https://api.weixin.qq.com/cgi-bin/wxverify/checkwxverifynickname"
+	queryNicknameURL       = "https://api.weixin.qq.com/wxa/api_wxa_querynickname"
+	modifyHeadImageURL     = "https://api.weixin.qq.com/cgi-bin/account/modifyheadimage"
+	modifySignatureURL     = "https://api.weixin.qq.com/cgi-bin/account/modifysignature"
+	maxNamingOtherStuffNum = 5
+)
+
+// NicknameAuditStatus 名称审核状态
+type NicknameAuditStatus int
+
+const (
+	// NicknameAuditing 审核中
+	NicknameAuditing NicknameAuditStatus = 1
+	// NicknameAuditRejected 审核驳回
+	NicknameAuditRejected NicknameAuditStatus = 2
+	// NicknameAuditPassed 审核通过
+	NicknameAuditPassed NicknameAuditStatus = 3
+)
+
+// SetNicknameParam 设置名称参数，证明材料可传 media_id 或本地文件路径，传文件时自动上传为临时素材
+type SetNicknameParam struct {
+	NickName string // 名称
+
+	IDCard     string // 身份证照片 media_id，个人号必填
+	IDCardFile string // 身份证照片文件
+
+	License     string // 组织机构代码证或营业执照 media_id，组织号必填
+	LicenseFile string // 组织机构代码证或营业执照文件
+
+	NamingOtherStuff      []string // 其它证明材料 media_id，最多 5 个
+	NamingOtherStuffFiles []string // 其它证明材料文件
+}
+
+// SetNicknameResult 设置名称结果
+type SetNicknameResult struct {
+	util.CommonError
+	Wording string `json:"wording"`  // 材料说明
+	AuditID int64  `json:"audit_id"` // 审核单 id，为 0 时表示名称已直接设置成功，无需审核
+}
+
+// CheckNicknameResult 微信认证名称检测结果
+type CheckNicknameResult struct {
+	util.CommonError
+	HitCondition bool   `json:"hit_condition"` // 是否命中关键字策略，命中时需要提交证明材料
+	Wording      string `json:"wording"`       // 命中关键字的说明描述
+}
+
+// NicknameAuditResult 名称审核结果
+type NicknameAuditResult struct {
+	util.CommonError
+	Nickname   string              `json:"nickname"`    // 审核的名称
+	AuditStat  NicknameAuditStatus `json:"audit_stat"`  // 审核状态
+	FailReason string              `json:"fail_reason"` // 驳回原因
+	CreateTime int64               `json:"create_time"` // 提交审核的时间
+	AuditTime  int64               `json:"audit_time"`  // 审核通过或驳回的时间
+}
+
+// ModifyHeadImageParam 修改头像参数，裁剪框坐标为 0-1 的比例，不裁剪时传 0,0,1,1
+type ModifyHeadImageParam struct {
+	HeadImgMediaID string  // 头像 media_id
+	HeadImgFile    string  // 头像文件，HeadImgMediaID 为空时上传
+	X1, Y1, X2, Y2 float64 // 裁剪框左上角和右下角坐标
+}
+
+// Material 使用授权小程序 access_token 的素材管理，可用于上传临时素材获取 media_id
+func (m *MiniPrograms) Material() *material.Material {
+	ctx := m.NewAuthorizerContext(m.AuthAppID)
+	ctx.SetGetAccessTokenFunc(func(*context.Context) (string, error) {
+		return m.accessToken()
+	})
+	return material.NewMaterial(ctx)
+}
+
+// UploadImage 上传图片临时素材，返回 media_id
+func (m *MiniPrograms) UploadImage(filename string) (mediaID string, err error) {
+	media, err := m.Material().MediaUpload(material.MediaTypeImage, filename)
+	if err != nil {
+		return
+	}
+	mediaID = media.MediaID
+	return
+}
+
+// uploadIfNeeded mediaID 为空且设置了文件时上传文件
+func (m *MiniPrograms) uploadIfNeeded(mediaID, filename string) (string, error) {
+	if mediaID != "" || filename == "" {
+		return mediaID, nil
+	}
+	return m.UploadImage(filename)
+}
+
+// SetNickname 设置小程序名称，命中关键字策略时需要提交证明材料并等待审核
+func (m *MiniPrograms) SetNickname(param SetNicknameParam) (ret SetNicknameResult, err error) {
+	if param.NickName == "" {
+		err = fmt.Errorf("nick_name is empty")
+		return
+	}
+	// 先检查数量，避免上传后才发现超出
+	if len(param.NamingOtherStuff)+len(param.NamingOtherStuffFiles) > maxNamingOtherStuffNum {
+		err = fmt.Errorf("naming_other_stuff exceeds %d", maxNamingOtherStuffNum)
+		return
+	}
+	req := map[string]string{
+		"nick_name": param.NickName,
+	}
+	if req["id_card"], err = m.uploadIfNeeded(param.IDCard, param.IDCardFile); err != nil {
+		return
+	}
+	if req["license"], err = m.uploadIfNeeded(param.License, param.LicenseFile); err != nil {
+		return
+	}
+	stuff := append([]string{}, param.NamingOtherStuff...)
+	for _, file := range param.NamingOtherStuffFiles {
+		var mediaID string
+		if mediaID, err = m.UploadImage(file); err != nil {
+			return
+		}
+		stuff = append(stuff, mediaID)
+	}
+	for i, mediaID := range stuff {
+		req[fmt.Sprintf("naming_other_stuff_%d", i+1)] = mediaID
+	}
+	for k, v := range req {
+		if v == "" {
+			delete(req, k)
+		}
+	}
+	body, err := m.post(setNicknameURL, req)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// CheckNickname 微信认证名称检测
+func (m *MiniPrograms) CheckNickname(nickname string) (ret CheckNicknameResult, err error) {
+	body, err := m.post(checkNicknameURL, map[string]string{
+		"nick_name": nickname,
+	})
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// QueryNickname 查询改名审核状态，auditID 为 SetNickname 返回的审核单 id
+func (m *MiniPrograms) QueryNickname(auditID int64) (ret NicknameAuditResult, err error) {
+	body, err := m.post(queryNicknameURL, map[string]int64{
+		"audit_id": auditID,
+	})
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// ModifyHeadImage 修改头像
+func (m *MiniPrograms) ModifyHeadImage(param ModifyHeadImageParam) (err error) {
+	mediaID, err := m.uploadIfNeeded(param.HeadImgMediaID, param.HeadImgFile)
+	if err != nil {
+		return
+	}
+	if mediaID == "" {
+		return fmt.Errorf("head_img_media_id is empty")
+	}
+	body, err := m.post(modifyHeadImageURL, map[string]interface{}{
+		"head_img_media_id": mediaID,
+		"x1":                param.X1,
+		"y1":                param.Y1,
+		"x2":                param.X2,
+		"y2":                param.Y2,
+	})
+	if err != nil {
+		return
+	}
+	ret := util.CommonError{}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// ModifySignature 修改功能介绍
+func (m *MiniPrograms) ModifySignature(signature string) (err error) {
+	if signature == "" {
+		return fmt.Errorf("signature is empty")
+	}
+	body, err := m.post(modifySignatureURL, map[string]string{
+		"signature": signature,
+	})
+	if err != nil {
+		return
+	}
+	ret := util.CommonError{}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
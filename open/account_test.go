@@ -0,0 +1,28 @@
+package open
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAccountParamCheck(t *testing.T) {
+	m := &MiniPrograms{}
+	if _, err := m.SetNickname(SetNicknameParam{}); err == nil || !strings.Contains(err.Error(), "nick_name") {
+		t.Errorf("empty nickname err = %v", err)
+	}
+	// 超出数量时在上传文件前返回错误
+	param := SetNicknameParam{
+		NickName:              "name",
+		NamingOtherStuff:      []string{"m1", "m2", "m3", "m4"},
+		NamingOtherStuffFiles: []string{"f1.png", "f2.png"},
+	}
+	if _, err := m.SetNickname(param); err == nil || !strings.Contains(err.Error(), "naming_other_stuff") {
+		t.Errorf("too many naming_other_stuff err = %v", err)
+	}
+	if err := m.ModifyHeadImage(ModifyHeadImageParam{X2: 1, Y2: 1}); err == nil || !strings.Contains(err.Error(), "head_img_media_id") {
+		t.Errorf("empty head image err = %v", err)
+	}
+	if err := m.ModifySignature(""); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("empty signature err = %v", err)
+	}
+}
@@ -9,6 +9,10 @@ import (
 const (
 	getcategoryURL      = "https://api.weixin.qq.com/cgi-bin/wxopen/getcategory"
 	getAuditCategoryURL = "https://api.weixin.qq.com/wxa/get_category"
+	getAllCategoriesURL = "https://api.weixin.qq.com/cgi-bin/wxopen/getallcategories"
+	addCategoryURL      = "https://api.weixin.qq.com/cgi-bin/wxopen/addcategory"
+	deleteCategoryURL   = "https://api.weixin.qq.com/cgi-bin/wxopen/deletecategory"
+	modifyCategoryURL   = "https://api.weixin.qq.com/cgi-bin/wxopen/modifycategory"
 )
 
 // Category 小程序类目
@@ -84,3 +88,135 @@ func (m *MiniPrograms) GetAuditCategory() (result []AuditCategoryInfo, err error
 	result = ret.CategoryList
 	return
 }
+
+// AllCategory 可以设置的所有类目
+type AllCategory struct {
+	util.CommonError
+	CategoriesList struct {
+		Categories []AllCategoryInfo `json:"categories"`
+	} `json:"categories_list"`
+}
+
+// AllCategoryInfo 可设置的类目及所需资质
+type AllCategoryInfo struct {
+	ID            int             `json:"id"`
+	Name          string          `json:"name"`
+	Level         int             `json:"level"`
+	Father        int             `json:"father"`
+	Children      []int           `json:"children"`
+	SensitiveType int             `json:"sensitive_type"` // 是否为敏感类目，1 为敏感类目需要提供资质证明
+	Qualify       CategoryQualify `json:"qualify"`
+	Scope         string          `json:"scope"`
+}
+
+// CategoryQualify 类目所需资质，exter_list 中每一组至少提供一项
+type CategoryQualify struct {
+	ExterList []struct {
+		InnerList []struct {
+			Name string `json:"name"` // 资质名称
+			URL  string `json:"url"`  // 资质示例
+		} `json:"inner_list"`
+	} `json:"exter_list"`
+	Remark string `json:"remark"`
+}
+
+// CategoryCertificate 类目资质，Value 为资质图片的 media_id
+// 设置 File 且 Value 为空时自动上传为临时素材
+type CategoryCertificate struct {
+	Key   string `json:"key"`   // 资质名称
+	Value string `json:"value"` // 资质图片 media_id
+	File  string `json:"-"`     // 资质图片文件
+}
+
+// CategoryParam 新增或修改的类目
+type CategoryParam struct {
+	First        int                   `json:"first"`  // 一级类目 ID
+	Second       int                   `json:"second"` // 二级类目 ID
+	Certificates []CategoryCertificate `json:"certicates,omitempty"`
+}
+
+// GetAllCategories 获取可以设置的所有类目及所需资质
+func (m *MiniPrograms) GetAllCategories() (result []AllCategoryInfo, err error) {
+	var body []byte
+	body, err = m.get(getAllCategoriesURL, nil)
+	if err != nil {
+		return
+	}
+	var ret AllCategory
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+		return
+	}
+	result = ret.CategoriesList.Categories
+	return
+}
+
+// AddCategory 添加类目，敏感类目需要提供资质图片
+func (m *MiniPrograms) AddCategory(categories ...CategoryParam) (err error) {
+	params := make([]CategoryParam, len(categories))
+	copy(params, categories)
+	for i := range params {
+		if params[i].Certificates, err = m.uploadCertificates(params[i].Certificates); err != nil {
+			return
+		}
+	}
+	return m.postCategory(addCategoryURL, map[string]interface{}{
+		"categories": params,
+	})
+}
+
+// DeleteCategory 删除类目
+func (m *MiniPrograms) DeleteCategory(first, second int) (err error) {
+	return m.postCategory(deleteCategoryURL, map[string]int{
+		"first":  first,
+		"second": second,
+	})
+}
+
+// ModifyCategory 修改类目资质信息
+func (m *MiniPrograms) ModifyCategory(category CategoryParam) (err error) {
+	if category.Certificates, err = m.uploadCertificates(category.Certificates); err != nil {
+		return
+	}
+	return m.postCategory(modifyCategoryURL, category)
+}
+
+// UploadQualification 上传类目资质图片，返回可用于 CategoryCertificate.Value 的 media_id
+func (m *MiniPrograms) UploadQualification(filename string) (mediaID string, err error) {
+	return m.UploadImage(filename)
+}
+
+// uploadCertificates 上传未填写 media_id 的资质图片，返回填写了 media_id 的副本，不修改传入的切片
+func (m *MiniPrograms) uploadCertificates(certificates []CategoryCertificate) (ret []CategoryCertificate, err error) {
+	if len(certificates) == 0 {
+		return certificates, nil
+	}
+	ret = make([]CategoryCertificate, len(certificates))
+	copy(ret, certificates)
+	for i := range ret {
+		if ret[i].Value, err = m.uploadIfNeeded(ret[i].Value, ret[i].File); err != nil {
+			return nil, err
+		}
+	}
+	return
+}
+
+func (m *MiniPrograms) postCategory(urlStr string, req interface{}) (err error) {
+	body, err := m.post(urlStr, req)
+	if err != nil {
+		return
+	}
+	ret := util.CommonError{}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
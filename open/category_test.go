@@ -0,0 +1,30 @@
+package open
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestUploadCertificatesCopy(t *testing.T) {
+	m := &MiniPrograms{}
+	certificates := []CategoryCertificate{{Key: "许可证", Value: "media"}}
+	ret, err := m.uploadCertificates(certificates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ret[0].Value = "changed"
+	if certificates[0].Value != "media" {
+		t.Error("uploadCertificates should not modify the caller's slice")
+	}
+}
+
+func TestCategoryParamWithoutCertificates(t *testing.T) {
+	data, err := json.Marshal(CategoryParam{First: 1, Second: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "certicates") {
+		t.Errorf("nil certificates should be omitted, got %s", data)
+	}
+}
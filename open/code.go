@@ -66,12 +66,17 @@ type ApplyPrivacyInterfaceParam struct {
 	PicList []string `json:"pic_list"` //(辅助图片)填写图片的url ，最多10个
 }
 
-// CommitParamExt 此处还能支持更多，不过貌似没啥用
+// CommitParamExt 第三方自定义的配置 ext.json，上传代码时序列化为 ext_json
 type CommitParamExt struct {
-	ExtAppID             string            `json:"extAppid"` // appid
-	ExtEnable            bool              `json:"extEnable"`
-	RequiredPrivateInfos []string          `json:"requiredPrivateInfos"`
-	Ext                  map[string]string `json:"ext"` // 附加扩展配置
+	ExtAppID             string                            `json:"extAppid"` // appid
+	ExtEnable            bool                              `json:"extEnable"`
+	DirectCommit         bool                              `json:"directCommit,omitempty"` // 是否直接提交到待审核列表
+	RequiredPrivateInfos []string                          `json:"requiredPrivateInfos"`
+	Ext                  map[string]string                 `json:"ext"`                      // 附加扩展配置
+	ExtPages             map[string]map[string]interface{} `json:"extPages,omitempty"`       // 单独设置页面的配置，key 为页面路径
+	Window               *ExtWindow                        `json:"window,omitempty"`         // 全局默认窗口表现
+	TabBar               *ExtTabBar                        `json:"tabBar,omitempty"`         // 底部 tab 栏
+	NetworkTimeout       *ExtNetworkTimeout                `json:"networkTimeout,omitempty"` // 网络请求的超时时间
 }
 
 // CodePageList 已上传的代码的页面列表
@@ -201,9 +206,14 @@ func (m *MiniPrograms) Commit(param CommitParam) (err error) {
 
 	//配置文件设置为true
 	param.Ext.ExtEnable = true
-	//配置
-	param.Ext.RequiredPrivateInfos = []string{"getLocation", "chooseLocation"}
+	//未设置时保持原有的默认配置
+	if param.Ext.RequiredPrivateInfos == nil {
+		param.Ext.RequiredPrivateInfos = []string{"getLocation", "chooseLocation"}
+	}
 
+	if err = param.Validate(); err != nil {
+		return
+	}
 	if param.ExtJSON == "" {
 		var extJsonByte []byte
 		extJsonByte, err = json.Marshal(param.Ext)
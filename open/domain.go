@@ -0,0 +1,270 @@
+package open
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+const (
+	modifyDomainDirectlyURL        = "https://api.weixin.qq.com/wxa/modify_domain_directly"
+	setWebViewDomainDirectlyURL    = "https://api.weixin.qq.com/wxa/setwebviewdomain_directly"
+	getWebViewDomainConfirmFileURL = "https://api.weixin.qq.com/wxa/get_webviewdomain_confirmfile"
+	getComponentConfirmFileURL     = "https://api.weixin.qq.com/cgi-bin/component/get_domain_confirmfile"
+)
+
+// 域名类别
+const (
+	DomainKindRequest  = "requestdomain"
+	DomainKindSocket   = "wsrequestdomain"
+	DomainKindUpload   = "uploaddomain"
+	DomainKindDownload = "downloaddomain"
+	DomainKindWebView  = "webviewdomain"
+)
+
+// serverDomainKinds 服务器域名类别，通过 modify_domain 设置
+var serverDomainKinds = []string{DomainKindRequest, DomainKindSocket, DomainKindUpload, DomainKindDownload}
+
+// allDomainKinds 全部域名类别
+var allDomainKinds = []string{DomainKindRequest, DomainKindSocket, DomainKindUpload, DomainKindDownload, DomainKindWebView}
+
+// DomainSet 小程序的服务器域名和业务域名，字段为 nil 表示不管理该类域名，空切片表示删除全部
+type DomainSet struct {
+	RequestDomain   []string `json:"requestdomain"`
+	WSRequestDomain []string `json:"wsrequestdomain"`
+	UploadDomain    []string `json:"uploaddomain"`
+	DownloadDomain  []string `json:"downloaddomain"`
+	WebViewDomain   []string `json:"webviewdomain"`
+}
+
+func (d *DomainSet) kind(kind string) []string {
+	switch kind {
+	case DomainKindRequest:
+		return d.RequestDomain
+	case DomainKindSocket:
+		return d.WSRequestDomain
+	case DomainKindUpload:
+		return d.UploadDomain
+	case DomainKindDownload:
+		return d.DownloadDomain
+	case DomainKindWebView:
+		return d.WebViewDomain
+	}
+	return nil
+}
+
+// DomainChange 一类域名的变更
+type DomainChange struct {
+	Kind    string   // 域名类别，见 DomainKind*
+	Added   []string // 新增的域名
+	Removed []string // 删除的域名
+}
+
+// DomainReport EnsureDomains 的结果
+type DomainReport struct {
+	AppID   string
+	Changes []DomainChange // 只包含有变更的类别
+	Err     error          // 批量执行时单个小程序的错误
+}
+
+// Changed 是否有变更
+func (r *DomainReport) Changed() bool {
+	return len(r.Changes) > 0
+}
+
+// EnsureDomainsOpts EnsureDomains 的选项
+type EnsureDomainsOpts struct {
+	DryRun   bool // 只计算差异，不修改
+	Directly bool // 使用 modify_domain_directly 和 setwebviewdomain_directly，无需先在第三方平台配置域名
+}
+
+// DomainConfirmFile 业务域名校验文件，需放置在业务域名根目录
+type DomainConfirmFile struct {
+	util.CommonError
+	FileName    string `json:"file_name"`
+	FileContent string `json:"file_content"`
+}
+
+// GetDomains 获取当前的服务器域名和业务域名
+func (m *MiniPrograms) GetDomains() (ret DomainSet, err error) {
+	if err = m.postDomain(modifyDomainURL, map[string]string{"action": ActionGet}, &ret); err != nil {
+		return
+	}
+	var webView DomainSet
+	if err = m.postDomain(setWebViewDomainURL, map[string]string{"action": ActionGet}, &webView); err != nil {
+		return
+	}
+	ret.WebViewDomain = webView.WebViewDomain
+	return
+}
+
+// EnsureDomains 声明式设置域名：获取当前域名，与 desired 比较后只新增缺少的、删除多余的，并返回变更
+// desired 中没有协议的域名按类别补全（socket 域名为 wss://，其它为 https://）
+func (m *MiniPrograms) EnsureDomains(desired DomainSet, opts EnsureDomainsOpts) (report DomainReport, err error) {
+	report.AppID = m.AuthAppID
+	current, err := m.GetDomains()
+	if err != nil {
+		return
+	}
+	added, removed := map[string]interface{}{}, map[string]interface{}{}
+	for _, kind := range allDomainKinds {
+		want := desired.kind(kind)
+		if want == nil {
+			continue
+		}
+		change := DomainChange{Kind: kind}
+		change.Added, change.Removed = diffDomains(kind, current.kind(kind), want)
+		if len(change.Added) == 0 && len(change.Removed) == 0 {
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+		if len(change.Added) > 0 {
+			added[kind] = change.Added
+		}
+		if len(change.Removed) > 0 {
+			removed[kind] = change.Removed
+		}
+	}
+	if opts.DryRun || !report.Changed() {
+		return
+	}
+	// 先新增再删除，避免中途失败时线上请求没有可用域名
+	if err = m.applyDomains(ActionAdd, added, opts.Directly); err != nil {
+		return
+	}
+	err = m.applyDomains(ActionDelete, removed, opts.Directly)
+	return
+}
+
+// applyDomains 按类别新增或删除域名，服务器域名和业务域名分别调用对应接口
+func (m *MiniPrograms) applyDomains(action Action, domains map[string]interface{}, directly bool) (err error) {
+	if webView, ok := domains[DomainKindWebView]; ok {
+		urlStr := setWebViewDomainURL
+		if directly {
+			urlStr = setWebViewDomainDirectlyURL
+		}
+		if err = m.postDomain(urlStr, map[string]interface{}{"action": action, DomainKindWebView: webView}, nil); err != nil {
+			return
+		}
+	}
+	req := map[string]interface{}{"action": action}
+	for _, kind := range serverDomainKinds {
+		if list, ok := domains[kind]; ok {
+			req[kind] = list
+		}
+	}
+	if len(req) == 1 {
+		return
+	}
+	urlStr := modifyDomainURL
+	if directly {
+		urlStr = modifyDomainDirectlyURL
+	}
+	return m.postDomain(urlStr, req, nil)
+}
+
+// ModifyDomainDirectly 快速设置服务器域名，无需先在第三方平台配置小程序服务器域名
+func (m *MiniPrograms) ModifyDomainDirectly(param ModifyDomainParam) (err error) {
+	return m.postDomain(modifyDomainDirectlyURL, param, nil)
+}
+
+// SetWebViewDomainDirectly 快速设置业务域名，需先将 GetWebViewDomainConfirmFile 返回的校验文件放置在域名根目录
+func (m *MiniPrograms) SetWebViewDomainDirectly(param SetWebViewDomainURLParam) (err error) {
+	return m.postDomain(setWebViewDomainDirectlyURL, param, nil)
+}
+
+// GetWebViewDomainConfirmFile 获取小程序业务域名校验文件
+func (m *MiniPrograms) GetWebViewDomainConfirmFile() (ret DomainConfirmFile, err error) {
+	err = m.postDomain(getWebViewDomainConfirmFileURL, nil, &ret)
+	return
+}
+
+// GetDomainConfirmFile 获取第三方平台业务域名（跳转域名）校验文件
+func (o *Open) GetDomainConfirmFile() (ret DomainConfirmFile, err error) {
+	body, err := o.post(getComponentConfirmFileURL, nil)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// EnsureDomainsFor 为多个授权小程序设置同一组域名，授权信息从 AuthorizerStore 中获取
+// 单个小程序失败不影响其它小程序，错误记录在 DomainReport.Err 中
+func (o *Open) EnsureDomainsFor(appids []string, desired DomainSet, opts EnsureDomainsOpts) []DomainReport {
+	reports := make([]DomainReport, 0, len(appids))
+	for _, appid := range appids {
+		m, err := o.NewMiniProgramsByAppID(appid)
+		report := DomainReport{AppID: appid, Err: err}
+		if err == nil {
+			report, report.Err = m.EnsureDomains(desired, opts)
+		}
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// postDomain 调用域名相关接口，ret 为空时只检查错误码
+func (m *MiniPrograms) postDomain(urlStr string, req interface{}, ret interface{}) (err error) {
+	body, err := m.post(urlStr, req)
+	if err != nil {
+		return
+	}
+	var result util.CommonError
+	if err = json.Unmarshal(body, &result); err != nil {
+		return
+	}
+	if result.ErrCode != 0 {
+		return fmt.Errorf("[%d]: %s", result.ErrCode, result.ErrMsg)
+	}
+	if ret != nil {
+		err = json.Unmarshal(body, ret)
+	}
+	return
+}
+
+// diffDomains 计算需要新增和删除的域名，比较时忽略大小写和末尾的 /，没有协议的域名按类别补全协议
+func diffDomains(kind string, current, desired []string) (added, removed []string) {
+	have := make(map[string]bool, len(current))
+	for _, d := range current {
+		have[normalizeDomain(kind, d)] = true
+	}
+	want := make(map[string]bool, len(desired))
+	for _, d := range desired {
+		key := normalizeDomain(kind, d)
+		if !want[key] && !have[key] {
+			added = append(added, domainWithScheme(kind, d))
+		}
+		want[key] = true
+	}
+	for _, d := range current {
+		if !want[normalizeDomain(kind, d)] {
+			removed = append(removed, d)
+		}
+	}
+	return
+}
+
+// domainWithScheme 为没有协议的域名补全协议，socket 域名为 wss://，其它为 https://
+func domainWithScheme(kind, domain string) string {
+	domain = strings.TrimSpace(domain)
+	if strings.Contains(domain, "://") {
+		return domain
+	}
+	if kind == DomainKindSocket {
+		return "wss://" + domain
+	}
+	return "https://" + domain
+}
+
+func normalizeDomain(kind, domain string) string {
+	return strings.TrimRight(strings.ToLower(domainWithScheme(kind, domain)), "/")
+}
@@ -0,0 +1,122 @@
+package open
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDiffDomains(t *testing.T) {
+	current := []string{"https://a.com", "https://B.com/", "https://c.com"}
+	desired := []string{"https://b.com", "https://d.com", "https://d.com"}
+	added, removed := diffDomains(DomainKindRequest, current, desired)
+	if !reflect.DeepEqual(added, []string{"https://d.com"}) {
+		t.Errorf("added = %v", added)
+	}
+	if !reflect.DeepEqual(removed, []string{"https://a.com", "https://c.com"}) {
+		t.Errorf("removed = %v", removed)
+	}
+
+	added, removed = diffDomains(DomainKindRequest, current, []string{})
+	if len(added) != 0 || len(removed) != len(current) {
+		t.Errorf("empty desired should remove all, added = %v removed = %v", added, removed)
+	}
+
+	// 没有协议的域名按类别补全后比较
+	added, removed = diffDomains(DomainKindRequest, []string{"https://example.com"}, []string{"Example.com", "new.com"})
+	if !reflect.DeepEqual(added, []string{"https://new.com"}) || len(removed) != 0 {
+		t.Errorf("bare host: added = %v removed = %v", added, removed)
+	}
+	added, removed = diffDomains(DomainKindSocket, []string{"wss://ws.com"}, []string{"ws.com"})
+	if len(added) != 0 || len(removed) != 0 {
+		t.Errorf("bare socket host: added = %v removed = %v", added, removed)
+	}
+	added, removed = diffDomains(DomainKindSocket, []string{"https://ws.com"}, []string{"ws.com"})
+	if !reflect.DeepEqual(added, []string{"wss://ws.com"}) || !reflect.DeepEqual(removed, []string{"https://ws.com"}) {
+		t.Errorf("socket scheme: added = %v removed = %v", added, removed)
+	}
+}
+
+func TestDomainSetKind(t *testing.T) {
+	d := DomainSet{RequestDomain: []string{"https://a.com"}, WebViewDomain: []string{}}
+	if got := d.kind(DomainKindRequest); len(got) != 1 {
+		t.Errorf("request domain = %v", got)
+	}
+	if d.kind(DomainKindUpload) != nil {
+		t.Error("unset kind should be nil")
+	}
+	if d.kind(DomainKindWebView) == nil {
+		t.Error("empty webview domain should be managed")
+	}
+	if len(allDomainKinds) != 5 || len(serverDomainKinds) != 4 {
+		t.Errorf("unexpected kinds %v %v", allDomainKinds, serverDomainKinds)
+	}
+}
+
+func TestEnsureDomains(t *testing.T) {
+	o, _ := newTestOpen(t)
+	o.Cache.Set(o.CacheKeys().AuthorizerAccessToken("wx1"), "token", time.Hour)
+	m := o.NewMiniPrograms("wx1", "refresh")
+	var requests []string
+	stubHTTP(t, func(path string, body []byte) string {
+		var req map[string]interface{}
+		json.Unmarshal(body, &req)
+		action := req["action"]
+		delete(req, "action")
+		data, _ := json.Marshal(req)
+		requests = append(requests, fmt.Sprintf("%s %s %s", strings.TrimPrefix(path, "/wxa/"), action, data))
+		if action != "get" {
+			return `{"errcode":0,"errmsg":"ok"}`
+		}
+		if path == "/wxa/setwebviewdomain" {
+			return `{"errcode":0,"webviewdomain":["https://web.com"]}`
+		}
+		return `{"errcode":0,"requestdomain":["https://example.com","https://old.com"],"wsrequestdomain":["wss://ws.com"],` +
+			`"uploaddomain":["https://up.com"],"downloaddomain":[]}`
+	})
+
+	// UploadDomain、DownloadDomain 为 nil 不管理，WebViewDomain 为空切片删除全部
+	desired := DomainSet{
+		RequestDomain:   []string{"example.com", "https://new.com"},
+		WSRequestDomain: []string{"ws.com"},
+		WebViewDomain:   []string{},
+	}
+	wantChanges := []DomainChange{
+		{Kind: DomainKindRequest, Added: []string{"https://new.com"}, Removed: []string{"https://old.com"}},
+		{Kind: DomainKindWebView, Removed: []string{"https://web.com"}},
+	}
+	gets := []string{"modify_domain get {}", "setwebviewdomain get {}"}
+
+	report, err := m.EnsureDomains(desired, EnsureDomainsOpts{DryRun: true})
+	if err != nil || !reflect.DeepEqual(report.Changes, wantChanges) {
+		t.Fatalf("dry run changes = %+v, err = %v", report.Changes, err)
+	}
+	if !reflect.DeepEqual(requests, gets) {
+		t.Fatalf("dry run should only get domains, requests = %v", requests)
+	}
+
+	requests = nil
+	report, err = m.EnsureDomains(desired, EnsureDomainsOpts{})
+	if err != nil || !reflect.DeepEqual(report.Changes, wantChanges) {
+		t.Fatalf("changes = %+v, err = %v", report.Changes, err)
+	}
+	// 先新增再删除
+	want := append(gets,
+		`modify_domain add {"requestdomain":["https://new.com"]}`,
+		`setwebviewdomain delete {"webviewdomain":["https://web.com"]}`,
+		`modify_domain delete {"requestdomain":["https://old.com"]}`,
+	)
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q\nwant %q", requests, want)
+	}
+
+	// 没有变更时不修改
+	requests = nil
+	desired = DomainSet{RequestDomain: []string{"https://example.com", "old.com"}}
+	if report, err = m.EnsureDomains(desired, EnsureDomainsOpts{}); err != nil || report.Changed() || len(requests) != 2 {
+		t.Errorf("no change: report = %+v, err = %v, requests = %v", report, err, requests)
+	}
+}
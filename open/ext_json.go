@@ -0,0 +1,158 @@
+package open
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// 底部 tab 栏的数量限制
+	minTabBarItems = 2
+	maxTabBarItems = 5
+)
+
+// requiredPrivateInfoNames requiredPrivateInfos 支持的接口
+var requiredPrivateInfoNames = map[string]bool{
+	"getFuzzyLocation":              true,
+	"getLocation":                   true,
+	"onLocationChange":              true,
+	"startLocationUpdate":           true,
+	"startLocationUpdateBackground": true,
+	"chooseLocation":                true,
+	"choosePoi":                     true,
+	"chooseAddress":                 true,
+}
+
+// ExtWindow ext.json 中的 window 配置
+type ExtWindow struct {
+	NavigationBarBackgroundColor string `json:"navigationBarBackgroundColor,omitempty"` // 导航栏背景颜色，如 #000000
+	NavigationBarTextStyle       string `json:"navigationBarTextStyle,omitempty"`       // 导航栏标题颜色，仅支持 black / white
+	NavigationBarTitleText       string `json:"navigationBarTitleText,omitempty"`       // 导航栏标题文字内容
+	NavigationStyle              string `json:"navigationStyle,omitempty"`              // 导航栏样式，default / custom
+	BackgroundColor              string `json:"backgroundColor,omitempty"`              // 窗口的背景色
+	BackgroundTextStyle          string `json:"backgroundTextStyle,omitempty"`          // 下拉 loading 的样式，仅支持 dark / light
+	EnablePullDownRefresh        bool   `json:"enablePullDownRefresh,omitempty"`        // 是否开启全局的下拉刷新
+	OnReachBottomDistance        int    `json:"onReachBottomDistance,omitempty"`        // 页面上拉触底事件触发时距页面底部距离，单位 px
+}
+
+// ExtTabBarItem ext.json 中 tabBar 的 tab
+type ExtTabBarItem struct {
+	PagePath         string `json:"pagePath"`                   // 页面路径，必须在 pages 中先定义
+	Text             string `json:"text"`                       // tab 上按钮文字
+	IconPath         string `json:"iconPath,omitempty"`         // 图片路径
+	SelectedIconPath string `json:"selectedIconPath,omitempty"` // 选中时的图片路径
+}
+
+// ExtTabBar ext.json 中的 tabBar 配置
+type ExtTabBar struct {
+	Color           string          `json:"color,omitempty"`           // tab 上的文字默认颜色
+	SelectedColor   string          `json:"selectedColor,omitempty"`   // tab 上的文字选中时的颜色
+	BackgroundColor string          `json:"backgroundColor,omitempty"` // tab 的背景色
+	BorderStyle     string          `json:"borderStyle,omitempty"`     // tabbar 上边框的颜色，仅支持 black / white
+	Position        string          `json:"position,omitempty"`        // tabBar 的位置，仅支持 bottom / top
+	Custom          bool            `json:"custom,omitempty"`          // 自定义 tabBar
+	List            []ExtTabBarItem `json:"list"`                      // tab 的列表，最少 2 个、最多 5 个
+}
+
+// ExtNetworkTimeout ext.json 中的 networkTimeout 配置，单位毫秒
+type ExtNetworkTimeout struct {
+	Request       int `json:"request,omitempty"`
+	ConnectSocket int `json:"connectSocket,omitempty"`
+	UploadFile    int `json:"uploadFile,omitempty"`
+	DownloadFile  int `json:"downloadFile,omitempty"`
+}
+
+// Validate 上传代码前校验参数，设置了 ExtJSON 时只校验其为合法的 JSON 对象
+func (p CommitParam) Validate() error {
+	if p.UserVersion == "" {
+		return fmt.Errorf("commit param: user_version is empty")
+	}
+	if p.UserDesc == "" {
+		return fmt.Errorf("commit param: user_desc is empty")
+	}
+	if p.ExtJSON != "" {
+		var ext map[string]interface{}
+		if err := json.Unmarshal([]byte(p.ExtJSON), &ext); err != nil {
+			return fmt.Errorf("commit param: ext_json is not a valid json object, err=%v", err)
+		}
+		return nil
+	}
+	return p.Ext.Validate()
+}
+
+// Validate 校验 ext.json 配置
+func (e CommitParamExt) Validate() error {
+	if e.ExtAppID == "" {
+		return fmt.Errorf("ext.json: extAppid is empty")
+	}
+	for _, name := range e.RequiredPrivateInfos {
+		if !requiredPrivateInfoNames[name] {
+			return fmt.Errorf("ext.json: unsupported requiredPrivateInfos %q", name)
+		}
+	}
+	for page := range e.ExtPages {
+		if page == "" {
+			return fmt.Errorf("ext.json: extPages contains empty page path")
+		}
+	}
+	if e.Window != nil {
+		if err := e.Window.validate(); err != nil {
+			return err
+		}
+	}
+	if e.TabBar != nil {
+		if err := e.TabBar.validate(); err != nil {
+			return err
+		}
+	}
+	if t := e.NetworkTimeout; t != nil {
+		if t.Request < 0 || t.ConnectSocket < 0 || t.UploadFile < 0 || t.DownloadFile < 0 {
+			return fmt.Errorf("ext.json: networkTimeout must not be negative")
+		}
+	}
+	return nil
+}
+
+func (w *ExtWindow) validate() error {
+	if !oneOf(w.NavigationBarTextStyle, "black", "white") {
+		return fmt.Errorf("ext.json: window.navigationBarTextStyle must be black or white, got %q", w.NavigationBarTextStyle)
+	}
+	if !oneOf(w.NavigationStyle, "default", "custom") {
+		return fmt.Errorf("ext.json: window.navigationStyle must be default or custom, got %q", w.NavigationStyle)
+	}
+	if !oneOf(w.BackgroundTextStyle, "dark", "light") {
+		return fmt.Errorf("ext.json: window.backgroundTextStyle must be dark or light, got %q", w.BackgroundTextStyle)
+	}
+	return nil
+}
+
+func (t *ExtTabBar) validate() error {
+	if len(t.List) < minTabBarItems || len(t.List) > maxTabBarItems {
+		return fmt.Errorf("ext.json: tabBar.list must have %d-%d items, got %d", minTabBarItems, maxTabBarItems, len(t.List))
+	}
+	for i, item := range t.List {
+		if item.PagePath == "" || item.Text == "" {
+			return fmt.Errorf("ext.json: tabBar.list[%d] requires pagePath and text", i)
+		}
+	}
+	if !oneOf(t.BorderStyle, "black", "white") {
+		return fmt.Errorf("ext.json: tabBar.borderStyle must be black or white, got %q", t.BorderStyle)
+	}
+	if !oneOf(t.Position, "bottom", "top") {
+		return fmt.Errorf("ext.json: tabBar.position must be bottom or top, got %q", t.Position)
+	}
+	return nil
+}
+
+// oneOf 值为空或在可选值中
+func oneOf(v string, options ...string) bool {
+	if v == "" {
+		return true
+	}
+	for _, o := range options {
+		if v == o {
+			return true
+		}
+	}
+	return false
+}
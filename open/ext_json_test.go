@@ -0,0 +1,64 @@
+package open
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCommitParamValidate(t *testing.T) {
+	valid := CommitParam{
+		TemplateID:  1,
+		UserVersion: "1.0.0",
+		UserDesc:    "init",
+		Ext: CommitParamExt{
+			ExtAppID: "wx1",
+			Window:   &ExtWindow{NavigationBarTextStyle: "white"},
+			TabBar: &ExtTabBar{List: []ExtTabBarItem{
+				{PagePath: "pages/index/index", Text: "首页"},
+				{PagePath: "pages/mine/mine", Text: "我的"},
+			}},
+		},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]func(p *CommitParam){
+		"user_version":         func(p *CommitParam) { p.UserVersion = "" },
+		"extAppid":             func(p *CommitParam) { p.Ext.ExtAppID = "" },
+		"navigationBarText":    func(p *CommitParam) { p.Ext.Window = &ExtWindow{NavigationBarTextStyle: "red"} },
+		"tabBar.list":          func(p *CommitParam) { p.Ext.TabBar = &ExtTabBar{List: []ExtTabBarItem{{PagePath: "a", Text: "a"}}} },
+		"requiredPrivateInfos": func(p *CommitParam) { p.Ext.RequiredPrivateInfos = []string{"getUserInfo"} },
+		"ext_json":             func(p *CommitParam) { p.ExtJSON = `{"extAppid":` },
+	}
+	for name, mutate := range cases {
+		p := valid
+		mutate(&p)
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestCommitParamExtMarshal(t *testing.T) {
+	ext := CommitParamExt{
+		ExtAppID:       "wx1",
+		ExtEnable:      true,
+		Ext:            map[string]string{"shop_id": "1"},
+		ExtPages:       map[string]map[string]interface{}{"pages/index/index": {"navigationBarTitleText": "店铺"}},
+		NetworkTimeout: &ExtNetworkTimeout{Request: 10000},
+	}
+	data, err := json.Marshal(ext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"extAppid":"wx1"`, `"extPages":{"pages/index/index"`, `"networkTimeout":{"request":10000}`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("ext.json %s should contain %s", data, want)
+		}
+	}
+	if strings.Contains(string(data), "tabBar") || strings.Contains(string(data), "window") {
+		t.Errorf("unset window and tabBar should be omitted: %s", data)
+	}
+}
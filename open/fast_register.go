@@ -0,0 +1,137 @@
+package open
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+	"github.com/pengshang1995/wechat-sdk/message"
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+const (
+	fastRegisterWeAppSearchURL = "https://api.weixin.qq.com/cgi-bin/component/fastregisterweapp?action=search"
+	// FastRegisterAuthPageURL 复用公众号主体快速注册小程序的授权页，公众号管理员扫码确认后跳转到 redirect_uri 并带上 ticket
+	FastRegisterAuthPageURL = "https://mp.weixin.qq.com/cgi-bin/fastregisterauth?component_appid=%s&appid=%s&copy_wx_verify=%d&redirect_uri=%s"
+	fastRegisterByOAURL     = "https://api.weixin.qq.com/cgi-bin/account/fastregister"
+)
+
+// FastRegisterSearchParam 查询快速注册任务的参数，与创建任务时一致
+type FastRegisterSearchParam struct {
+	CompanyName        string `json:"name"`
+	LegalPersonaWechat string `json:"legal_persona_wechat"`
+	LegalPersonaName   string `json:"legal_persona_name"`
+}
+
+// FastRegisterResult 快速注册小程序结果（notify_third_fasteregister 推送）
+type FastRegisterResult struct {
+	AppID    string // 注册成功的小程序 appid
+	Status   int64  // 0 为成功，其它为失败的错误码
+	AuthCode string // 第三方授权码，可用于换取授权信息
+	Msg      string // 错误信息
+	Info     FastRegisterWeAppParam
+}
+
+// Success 是否注册成功
+func (r *FastRegisterResult) Success() bool {
+	return r.Status == 0
+}
+
+// TaskKey 与 FastRegisterWeAppParam.TaskKey 相同，用于关联创建的任务
+func (r *FastRegisterResult) TaskKey() string {
+	return r.Info.TaskKey()
+}
+
+// TaskKey 快速注册任务的标识，企业名称、法人微信号和法人姓名确定一个任务
+func (p FastRegisterWeAppParam) TaskKey() string {
+	return p.CompanyName + "|" + p.LegalPersonaWechat + "|" + p.LegalPersonaName
+}
+
+// FastRegisterByOAResult 复用公众号主体快速注册小程序的结果
+type FastRegisterByOAResult struct {
+	util.CommonError
+	AppID             string `json:"appid"`              // 新创建小程序的 appid
+	AuthorizationCode string `json:"authorization_code"` // 新创建小程序的授权码
+	IsWxVerifySucc    bool   `json:"is_wx_verify_succ"`  // 复用公众号微信认证是否成功
+	IsLinkSucc        bool   `json:"is_link_succ"`       // 小程序是否与公众号关联成功
+}
+
+// SearchFastRegisterWeApp 查询快速注册任务的状态，任务仍在进行或失败时返回对应错误码的错误
+func (o *Open) SearchFastRegisterWeApp(param FastRegisterSearchParam) (ret util.CommonError, err error) {
+	requestURL, err := o.buildRequestV2(fastRegisterWeAppSearchURL, nil)
+	if err != nil {
+		return
+	}
+	body, err := util.PostJSON(requestURL, param)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// ParseFastRegisterNotify 解析快速注册结果推送，msg 不是 notify_third_fasteregister 时返回错误
+func ParseFastRegisterNotify(msg *message.MixMessage) (*FastRegisterResult, error) {
+	if msg.InfoType != message.NotifyThirdFasteregister {
+		return nil, fmt.Errorf("unexpected info type %s", msg.InfoType)
+	}
+	info := msg.MiniProgramRegInfo
+	return &FastRegisterResult{
+		AppID:    msg.MiniProgramAppid,
+		Status:   msg.MiniProgramStatus,
+		AuthCode: msg.MiniProgramAuthCode,
+		Msg:      msg.MiniProgramMsg,
+		Info: FastRegisterWeAppParam{
+			CompanyName:        info.CompanyName,
+			CompanyCode:        info.CompanyCode,
+			CodeType:           int(info.CodeType),
+			LegalPersonaWechat: info.LegalPersonaWechat,
+			LegalPersonaName:   info.LegalPersonaName,
+			ComponentPhone:     info.ComponentPhone,
+		},
+	}, nil
+}
+
+// CompleteFastRegister 注册成功后使用授权码保存新小程序的授权信息到 AuthorizerStore
+func (o *Open) CompleteFastRegister(result *FastRegisterResult) (*context.Authorizer, error) {
+	if !result.Success() {
+		return nil, fmt.Errorf("[%d]: %s", result.Status, result.Msg)
+	}
+	return o.SaveAuthorizationCode(result.AuthCode)
+}
+
+// FastRegisterAuthURL 复用公众号主体快速注册小程序的授权页地址，oaAppID 为已授权给第三方平台的公众号
+// copyWxVerify 为 true 时复用公众号的微信认证（小程序无需再次认证）
+func (o *Open) FastRegisterAuthURL(oaAppID string, copyWxVerify bool, redirectURI string) string {
+	copyVerify := 0
+	if copyWxVerify {
+		copyVerify = 1
+	}
+	return fmt.Sprintf(FastRegisterAuthPageURL, o.AppID, oaAppID, copyVerify, url.QueryEscape(redirectURI))
+}
+
+// FastRegisterByOfficialAccount 复用公众号主体快速注册小程序，ticket 为授权页跳转时带回的参数
+// m 为公众号的代调用句柄（NewMiniPrograms 或 NewMiniProgramsByAppID 传入公众号 appid）
+func (m *MiniPrograms) FastRegisterByOfficialAccount(ticket string) (ret FastRegisterByOAResult, err error) {
+	body, err := m.post(fastRegisterByOAURL, map[string]string{
+		"ticket": ticket,
+	})
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
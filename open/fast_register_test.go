@@ -0,0 +1,51 @@
+package open
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/message"
+)
+
+func TestParseFastRegisterNotify(t *testing.T) {
+	raw := `<xml>
+<AppId><![CDATA[第三方平台appid]]></AppId>
+<CreateTime>1535442403</CreateTime>
+<InfoType><![CDATA[notify_third_fasteregister]]></InfoType>
+<appid>wx1</appid>
+<status>0</status>
+<auth_code>xxx</auth_code>
+<msg>OK</msg>
+<info>
+<name><![CDATA[某公司]]></name>
+<code><![CDATA[123]]></code>
+<code_type>1</code_type>
+<legal_persona_wechat><![CDATA[wechat]]></legal_persona_wechat>
+<legal_persona_name><![CDATA[张三]]></legal_persona_name>
+<component_phone><![CDATA[1234567]]></component_phone>
+</info>
+</xml>`
+	msg := &message.MixMessage{}
+	if err := xml.Unmarshal([]byte(raw), msg); err != nil {
+		t.Fatal(err)
+	}
+	result, err := ParseFastRegisterNotify(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success() || result.AppID != "wx1" || result.AuthCode != "xxx" {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	param := FastRegisterWeAppParam{CompanyName: "某公司", LegalPersonaWechat: "wechat", LegalPersonaName: "张三"}
+	if result.TaskKey() != param.TaskKey() {
+		t.Fatalf("task key %s, want %s", result.TaskKey(), param.TaskKey())
+	}
+	if result.Info.ComponentPhone != "1234567" || result.Info.CodeType != 1 {
+		t.Fatalf("unexpected info %+v", result.Info)
+	}
+
+	msg.InfoType = message.InfoTypeAuthorized
+	if _, err = ParseFastRegisterNotify(msg); err == nil {
+		t.Fatal("expected error for other info type")
+	}
+}
@@ -1,9 +1,11 @@
 package open
 
 import (
+	"fmt"
 	"github.com/pengshang1995/wechat-sdk/context"
 	"github.com/pengshang1995/wechat-sdk/util"
 	"net/url"
+	"time"
 )
 
 const (
@@ -79,6 +81,21 @@ func (o *Open) NewMiniPrograms(appid string, refrshToken string) *MiniPrograms {
 	return miniPrograms
 }
 
+// NewMiniProgramsByAppID 使用 AuthorizerStore 中保存的授权信息创建代小程序句柄
+func (o *Open) NewMiniProgramsByAppID(appid string) (*MiniPrograms, error) {
+	if o.AuthorizerStore == nil {
+		return nil, fmt.Errorf("authorizer store is not set")
+	}
+	authorizer, err := o.AuthorizerStore.GetAuthorizer(appid)
+	if err != nil {
+		return nil, err
+	}
+	if authorizer.RefreshToken == "" {
+		return nil, fmt.Errorf("authorizer %s has no refresh token", appid)
+	}
+	return o.NewMiniPrograms(authorizer.AppID, authorizer.RefreshToken), nil
+}
+
 func (o *Open) buildRequest(urlStr string, param map[string]string) (requestURL string, err error) {
 	accessToken, err := o.GetComponentAccessToken()
 	if err != nil {
@@ -138,8 +155,28 @@ func (o *Open) get(urlStr string, param map[string]string) (response []byte, err
 	return
 }
 
-func (m *MiniPrograms) buildRequest(urlStr string, param map[string]string) (requestURL string, err error) {
-	accessToken, err := m.GetAuthrAccessToken(m.AuthAppID)
+// accessToken 获取授权小程序的 access_token
+func (m *MiniPrograms) accessToken() (accessToken string, err error) {
+	if m.AuthorizerStore != nil {
+		// 使用 AuthorizerStore 时 refresh_token 可能已更新，以存储中的为准，不能回退到创建时的 refresh_token
+		accessToken, err = m.GetAuthorizerAccessToken(m.AuthAppID)
+		if err != context.ErrAuthorizerNotFound || m.AuthRefreshToken == "" {
+			return
+		}
+		// 启用存储前授权的授权方，使用创建时的 refresh_token 写入存储后再刷新
+		now := time.Now()
+		err = m.AuthorizerStore.SaveAuthorizer(&context.Authorizer{
+			AppID:        m.AuthAppID,
+			RefreshToken: m.AuthRefreshToken,
+			AuthorizedAt: now,
+			UpdatedAt:    now,
+		})
+		if err != nil {
+			return
+		}
+		return m.GetAuthorizerAccessToken(m.AuthAppID)
+	}
+	accessToken, err = m.GetAuthrAccessToken(m.AuthAppID)
 	if err != nil {
 		var ret *context.AuthrAccessToken
 		ret, err = m.RefreshAuthrToken(m.AuthAppID, m.AuthRefreshToken)
@@ -148,6 +185,14 @@ func (m *MiniPrograms) buildRequest(urlStr string, param map[string]string) (req
 		}
 		accessToken = ret.AccessToken
 	}
+	return
+}
+
+func (m *MiniPrograms) buildRequest(urlStr string, param map[string]string) (requestURL string, err error) {
+	accessToken, err := m.accessToken()
+	if err != nil {
+		return
+	}
 	u, err := url.Parse(urlStr)
 	qs := u.Query()
 	qs.Add("access_token", accessToken)
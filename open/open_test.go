@@ -0,0 +1,94 @@
+package open
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/cache"
+	"github.com/pengshang1995/wechat-sdk/context"
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+func newTestOpen(t *testing.T) (*Open, *context.CacheAuthorizerStore) {
+	t.Helper()
+	mem := cache.NewMemory()
+	store := context.NewCacheAuthorizerStore(mem, "")
+	return NewOpen(&context.Context{AppID: "component", Cache: mem, AuthorizerStore: store}), store
+}
+
+func TestNewMiniProgramsByAppIDWithoutRefreshToken(t *testing.T) {
+	o, store := newTestOpen(t)
+	if err := store.SaveAuthorizer(&context.Authorizer{AppID: "wx1"}); err != nil {
+		t.Fatal(err)
+	}
+	if m, err := o.NewMiniProgramsByAppID("wx1"); err == nil || m != nil {
+		t.Errorf("NewMiniProgramsByAppID = %v, %v, want error", m, err)
+	}
+}
+
+func TestMiniProgramsAccessTokenWithStore(t *testing.T) {
+	o, store := newTestOpen(t)
+	o.Cache.Set(o.CacheKeys().ComponentAccessToken(o.AppID), "component_token", time.Hour)
+	var refreshBody string
+	stubHTTP(t, func(path string, body []byte) string {
+		refreshBody = string(body)
+		return `{"authorizer_access_token":"token","expires_in":7200,"authorizer_refresh_token":"refreshed"}`
+	})
+
+	// 启用存储前授权的授权方，使用创建时的 refresh_token 写入存储并刷新
+	m := o.NewMiniPrograms("wx1", "legacy")
+	if token, err := m.accessToken(); err != nil || token != "token" {
+		t.Fatalf("accessToken = %s, %v", token, err)
+	}
+	if !strings.Contains(refreshBody, `"authorizer_refresh_token":"legacy"`) {
+		t.Errorf("refresh body = %s", refreshBody)
+	}
+	if a, err := store.GetAuthorizer("wx1"); err != nil || a.RefreshToken != "refreshed" {
+		t.Errorf("authorizer = %+v, %v", a, err)
+	}
+
+	// 已在存储中的授权方以存储的 refresh_token 为准，不使用创建时的
+	now := time.Now()
+	store.SaveAuthorizer(&context.Authorizer{AppID: "wx2", RefreshToken: "current", AuthorizedAt: now, UpdatedAt: now})
+	m = o.NewMiniPrograms("wx2", "stale")
+	if _, err := m.accessToken(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(refreshBody, `"authorizer_refresh_token":"current"`) {
+		t.Errorf("refresh body = %s", refreshBody)
+	}
+
+	// 没有 refresh_token 时无法写入存储
+	m = &MiniPrograms{Open: *o, AuthAppID: "wx3"}
+	if _, err := m.accessToken(); err != context.ErrAuthorizerNotFound {
+		t.Errorf("accessToken err = %v, want ErrAuthorizerNotFound", err)
+	}
+}
+
+// stubHTTP 将接口请求转给 handler，返回值作为响应内容
+func stubHTTP(t *testing.T, handler func(path string, body []byte) string) {
+	t.Helper()
+	old := util.HTTPTransport
+	util.HTTPTransport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var body []byte
+		if r.Body != nil {
+			body, _ = ioutil.ReadAll(r.Body)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(strings.NewReader(handler(r.URL.Path, body))),
+			Request:    r,
+		}, nil
+	})
+	t.Cleanup(func() { util.HTTPTransport = old })
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
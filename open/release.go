@@ -0,0 +1,335 @@
+package open
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/cache"
+	"github.com/pengshang1995/wechat-sdk/message"
+)
+
+// ErrReleaseStateNotFound 小程序在任务中没有发布状态
+var ErrReleaseStateNotFound = errors.New("release state not found")
+
+// ReleaseStage 批量发布中单个小程序所处的阶段
+type ReleaseStage string
+
+const (
+	// ReleaseStagePending 待上传代码
+	ReleaseStagePending ReleaseStage = "pending"
+	// ReleaseStageCommitted 已上传代码，待提审
+	ReleaseStageCommitted ReleaseStage = "committed"
+	// ReleaseStageAuditing 审核中
+	ReleaseStageAuditing ReleaseStage = "auditing"
+	// ReleaseStageAuditPassed 审核通过，待发布
+	ReleaseStageAuditPassed ReleaseStage = "audit_passed"
+	// ReleaseStageReleased 已发布（或已开始分阶段发布）
+	ReleaseStageReleased ReleaseStage = "released"
+	// ReleaseStageFailed 审核被拒绝或已撤回，需人工处理
+	ReleaseStageFailed ReleaseStage = "failed"
+)
+
+// ReleaseAppState 单个小程序的发布状态
+type ReleaseAppState struct {
+	AppID      string       `json:"appid"`
+	Stage      ReleaseStage `json:"stage"`
+	AuditID    uint64       `json:"auditid,omitempty"`
+	Reason     string       `json:"reason,omitempty"`      // 审核被拒绝或延后的原因
+	ScreenShot string       `json:"screen_shot,omitempty"` // 审核被拒绝的截图 media_id，多个以 | 分隔
+	LastError  string       `json:"last_error,omitempty"`  // 最近一次调用接口的错误，下次 Run 时重试
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// Done 是否已结束（发布成功或失败）
+func (s *ReleaseAppState) Done() bool {
+	return s.Stage == ReleaseStageReleased || s.Stage == ReleaseStageFailed
+}
+
+// ReleaseStateStore 发布状态存储，进程重启后根据保存的状态继续发布
+type ReleaseStateStore interface {
+	// SaveReleaseState 保存小程序在任务中的状态
+	SaveReleaseState(taskID string, state *ReleaseAppState) error
+	// GetReleaseState 获取小程序在任务中的状态，不存在时返回 ErrReleaseStateNotFound
+	GetReleaseState(taskID, appid string) (*ReleaseAppState, error)
+}
+
+// CacheReleaseStateStore 基于 cache.Cache 的发布状态存储，数据永不过期
+type CacheReleaseStateStore struct {
+	cache cache.Cache
+	keys  cache.KeyBuilder
+}
+
+// NewCacheReleaseStateStore 创建基于缓存的发布状态存储，namespace 与 Config.CacheNamespace 一致
+func NewCacheReleaseStateStore(c cache.Cache, namespace string) *CacheReleaseStateStore {
+	return &CacheReleaseStateStore{cache: c, keys: cache.NewKeyBuilder(namespace)}
+}
+
+// SaveReleaseState 保存发布状态
+func (s *CacheReleaseStateStore) SaveReleaseState(taskID string, state *ReleaseAppState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return s.cache.Set(s.keys.ReleaseState(taskID, state.AppID), string(data), 0)
+}
+
+// GetReleaseState 获取发布状态
+func (s *CacheReleaseStateStore) GetReleaseState(taskID, appid string) (*ReleaseAppState, error) {
+	data, found, err := cache.GetString(stdcontext.Background(), cache.WithContext(s.cache), s.keys.ReleaseState(taskID, appid))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, ErrReleaseStateNotFound
+	}
+	state := new(ReleaseAppState)
+	if err = json.Unmarshal([]byte(data), state); err != nil {
+		return nil, fmt.Errorf("decode release state %s error, err=%v", appid, err)
+	}
+	return state, nil
+}
+
+// ReleasePlan 批量发布计划
+type ReleasePlan struct {
+	TaskID      string // 任务 ID，同一任务重复执行 Run 时从保存的状态继续
+	TemplateID  int    // 代码模板 ID
+	UserVersion string // 代码版本号
+	UserDesc    string // 版本描述
+	// BuildCommit 为每个小程序生成上传代码的参数（如不同的 ext_json），为空时使用 TemplateID 等字段和默认的 ext
+	BuildCommit func(appid string) (CommitParam, error)
+	// Audit 提审参数
+	Audit SubmitAuditParam
+	// GrayPercentage 分阶段发布的灰度比例（1-100），为 0 时全量发布
+	GrayPercentage int
+}
+
+// releaseClient 发布流程用到的代小程序接口，由 MiniPrograms 实现
+type releaseClient interface {
+	Commit(param CommitParam) error
+	SubmitAudit(param SubmitAuditParam) (uint64, error)
+	GetAuditStatus(auditID uint64) (AuditStatusResponse, error)
+	Release() error
+	GrayRelease(gray int) error
+	QueryQuota() (QueryQuotaResponse, error)
+}
+
+// Releaser 第三方平台批量发布小程序：上传代码、在提审额度内提审、跟踪审核结果、发布
+// 每次 Run 将每个小程序尽量向前推进，审核中的小程序需等待审核结果推送（HandleAuditEvent）或下次 Run 时查询
+//
+//	releaser := openInstance.NewReleaser(plan, open.NewCacheReleaseStateStore(cache, ""))
+//	report, err := releaser.Run(appids)
+type Releaser struct {
+	plan      ReleasePlan
+	store     ReleaseStateStore
+	newClient func(appid string) (releaseClient, error)
+}
+
+// NewReleaser 创建批量发布，授权信息从 AuthorizerStore 中获取
+func (o *Open) NewReleaser(plan ReleasePlan, store ReleaseStateStore) *Releaser {
+	return &Releaser{
+		plan:  plan,
+		store: store,
+		newClient: func(appid string) (releaseClient, error) {
+			m, err := o.NewMiniProgramsByAppID(appid)
+			if err != nil {
+				return nil, err
+			}
+			return m, nil
+		},
+	}
+}
+
+// ReleaseReport 一次 Run 的结果
+type ReleaseReport struct {
+	States     []*ReleaseAppState // 每个小程序执行后的状态，与传入的 appid 顺序一致
+	Errors     map[string]error   // 本次执行出错的小程序，下次 Run 时重试
+	QuotaRest  int                // 执行后剩余的提审额度，未查询额度时为 -1
+	QuotaLimit bool               // 是否有小程序因提审额度不足而未提审
+}
+
+// Done 是否全部小程序都已结束
+func (r *ReleaseReport) Done() bool {
+	for _, state := range r.States {
+		if !state.Done() {
+			return false
+		}
+	}
+	return true
+}
+
+// Run 推进一批小程序的发布，可重复调用直到 ReleaseReport.Done
+// 单个小程序的接口错误记录在 ReleaseReport.Errors 中，不影响其它小程序；读写状态存储出错时直接返回
+func (r *Releaser) Run(appids []string) (*ReleaseReport, error) {
+	if r.plan.TaskID == "" {
+		return nil, fmt.Errorf("release task id is empty")
+	}
+	// 未设置 BuildCommit 时所有小程序使用同一组参数，提前校验避免每个小程序都上传失败
+	if r.plan.BuildCommit == nil {
+		if r.plan.UserVersion == "" {
+			return nil, fmt.Errorf("release plan: user_version is empty")
+		}
+		if r.plan.UserDesc == "" {
+			return nil, fmt.Errorf("release plan: user_desc is empty")
+		}
+	}
+	report := &ReleaseReport{Errors: map[string]error{}, QuotaRest: -1}
+	for _, appid := range appids {
+		state, err := r.store.GetReleaseState(r.plan.TaskID, appid)
+		if err == ErrReleaseStateNotFound {
+			state, err = &ReleaseAppState{AppID: appid, Stage: ReleaseStagePending}, nil
+		}
+		if err != nil {
+			return report, err
+		}
+		if !state.Done() {
+			if err = r.advance(state, report); err != nil {
+				if storeErr, ok := err.(*releaseStoreError); ok {
+					return report, storeErr.err
+				}
+				report.Errors[appid] = err
+				state.LastError = err.Error()
+			} else {
+				state.LastError = ""
+			}
+			if err = r.save(state); err != nil {
+				return report, err
+			}
+		}
+		report.States = append(report.States, state)
+	}
+	return report, nil
+}
+
+// releaseStoreError advance 中保存状态失败，与接口错误区分，Run 遇到时直接返回
+type releaseStoreError struct {
+	err error
+}
+
+func (e *releaseStoreError) Error() string {
+	return e.err.Error()
+}
+
+// save 保存小程序的状态
+func (r *Releaser) save(state *ReleaseAppState) error {
+	state.UpdatedAt = time.Now()
+	return r.store.SaveReleaseState(r.plan.TaskID, state)
+}
+
+// advance 将小程序推进到无法继续的阶段，每次阶段变化后立即保存，进程中途退出时下次 Run 不会重复上传或提审
+func (r *Releaser) advance(state *ReleaseAppState, report *ReleaseReport) error {
+	client, err := r.newClient(state.AppID)
+	if err != nil {
+		return err
+	}
+	for {
+		switch state.Stage {
+		case ReleaseStagePending:
+			param, err := r.commitParam(state.AppID)
+			if err != nil {
+				return err
+			}
+			if err = client.Commit(param); err != nil {
+				return err
+			}
+			state.Stage = ReleaseStageCommitted
+		case ReleaseStageCommitted:
+			if report.QuotaRest < 0 {
+				quota, err := client.QueryQuota()
+				if err != nil {
+					return err
+				}
+				report.QuotaRest = quota.Rest
+			}
+			if report.QuotaRest <= 0 {
+				report.QuotaLimit = true
+				return nil
+			}
+			auditID, err := client.SubmitAudit(r.plan.Audit)
+			if err != nil {
+				return err
+			}
+			report.QuotaRest--
+			state.Stage = ReleaseStageAuditing
+			state.AuditID = auditID
+		case ReleaseStageAuditing:
+			status, err := client.GetAuditStatus(state.AuditID)
+			if err != nil {
+				return err
+			}
+			switch status.Status {
+			case 0:
+				state.Stage = ReleaseStageAuditPassed
+			case 1:
+				state.Stage = ReleaseStageFailed
+				state.Reason = status.Reason
+				state.ScreenShot = status.ScreenShot
+			case 3:
+				state.Stage = ReleaseStageFailed
+				state.Reason = "audit withdrawn"
+			default:
+				return nil
+			}
+		case ReleaseStageAuditPassed:
+			if r.plan.GrayPercentage > 0 {
+				err = client.GrayRelease(r.plan.GrayPercentage)
+			} else {
+				err = client.Release()
+			}
+			if err != nil {
+				return err
+			}
+			state.Stage = ReleaseStageReleased
+		default:
+			return nil
+		}
+		if err = r.save(state); err != nil {
+			return &releaseStoreError{err}
+		}
+	}
+}
+
+func (r *Releaser) commitParam(appid string) (CommitParam, error) {
+	if r.plan.BuildCommit != nil {
+		return r.plan.BuildCommit(appid)
+	}
+	param := CommitParam{
+		TemplateID:  r.plan.TemplateID,
+		UserVersion: r.plan.UserVersion,
+		UserDesc:    r.plan.UserDesc,
+	}
+	param.Ext.ExtAppID = appid
+	return param, nil
+}
+
+// HandleAuditEvent 处理审核结果推送（weapp_audit_success、weapp_audit_fail、weapp_audit_delay），
+// appid 为推送所属的小程序，通常从消息与事件接收 URL 中获取；其它事件或不在任务中的小程序忽略
+// 审核通过后不会立即发布，由下次 Run 发布
+func (r *Releaser) HandleAuditEvent(appid string, msg *message.MixMessage) error {
+	switch msg.Event {
+	case message.EventWeappAuditSuccess, message.EventWeappAuditFail, message.EventWeappAuditDelay:
+	default:
+		return nil
+	}
+	state, err := r.store.GetReleaseState(r.plan.TaskID, appid)
+	if err == ErrReleaseStateNotFound {
+		return nil
+	}
+	if err != nil || state.Stage != ReleaseStageAuditing {
+		return err
+	}
+	switch msg.Event {
+	case message.EventWeappAuditSuccess:
+		state.Stage = ReleaseStageAuditPassed
+	case message.EventWeappAuditFail:
+		state.Stage = ReleaseStageFailed
+		state.Reason = msg.Reason
+		state.ScreenShot = msg.ScreenShot
+	case message.EventWeappAuditDelay:
+		state.Reason = msg.Reason
+	}
+	state.UpdatedAt = time.Now()
+	return r.store.SaveReleaseState(r.plan.TaskID, state)
+}
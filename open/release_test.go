@@ -0,0 +1,209 @@
+package open
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/cache"
+	"github.com/pengshang1995/wechat-sdk/message"
+)
+
+type fakeReleaseClient struct {
+	quota     int
+	status    int
+	commitErr error
+	crash     bool // 查询审核状态时模拟进程退出
+	commits   int
+	submits   int
+	releases  int
+	gray      int
+}
+
+func (c *fakeReleaseClient) Commit(param CommitParam) error {
+	if c.commitErr != nil {
+		return c.commitErr
+	}
+	c.commits++
+	return nil
+}
+
+func (c *fakeReleaseClient) SubmitAudit(param SubmitAuditParam) (uint64, error) {
+	c.submits++
+	return uint64(c.submits), nil
+}
+
+func (c *fakeReleaseClient) GetAuditStatus(auditID uint64) (AuditStatusResponse, error) {
+	if c.crash {
+		panic("crash")
+	}
+	return AuditStatusResponse{AuditID: auditID, Status: c.status, Reason: "rejected"}, nil
+}
+
+func (c *fakeReleaseClient) Release() error {
+	c.releases++
+	return nil
+}
+
+func (c *fakeReleaseClient) GrayRelease(gray int) error {
+	c.gray = gray
+	return nil
+}
+
+func (c *fakeReleaseClient) QueryQuota() (QueryQuotaResponse, error) {
+	return QueryQuotaResponse{Rest: c.quota}, nil
+}
+
+func newTestReleaser(client *fakeReleaseClient, store ReleaseStateStore) *Releaser {
+	return &Releaser{
+		plan:  ReleasePlan{TaskID: "v1", TemplateID: 1, UserVersion: "1.0.0", UserDesc: "init"},
+		store: store,
+		newClient: func(appid string) (releaseClient, error) {
+			return client, nil
+		},
+	}
+}
+
+func TestReleaserRun(t *testing.T) {
+	store := NewCacheReleaseStateStore(cache.NewMemory(), "")
+	client := &fakeReleaseClient{quota: 1, status: 2}
+	r := newTestReleaser(client, store)
+
+	report, err := r.Run([]string{"wx1", "wx2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.States[0].Stage != ReleaseStageAuditing || report.States[1].Stage != ReleaseStageCommitted {
+		t.Fatalf("unexpected stages %s %s", report.States[0].Stage, report.States[1].Stage)
+	}
+	if !report.QuotaLimit || report.QuotaRest != 0 {
+		t.Fatalf("quota should be exhausted, got %+v", report)
+	}
+
+	// 重新执行时已上传的代码不会重复上传
+	client.quota = 5
+	client.status = 0
+	if report, err = r.Run([]string{"wx1", "wx2"}); err != nil {
+		t.Fatal(err)
+	}
+	if client.commits != 2 {
+		t.Fatalf("commits = %d, want 2", client.commits)
+	}
+	if !report.Done() || client.releases != 2 {
+		t.Fatalf("all apps should be released, releases = %d", client.releases)
+	}
+}
+
+func TestReleaserRunError(t *testing.T) {
+	store := NewCacheReleaseStateStore(cache.NewMemory(), "")
+	client := &fakeReleaseClient{commitErr: errors.New("[85013]: invalid ext_json")}
+	r := newTestReleaser(client, store)
+
+	report, err := r.Run([]string{"wx1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Errors["wx1"] == nil {
+		t.Fatal("commit error should be reported")
+	}
+	state, _ := store.GetReleaseState("v1", "wx1")
+	if state.Stage != ReleaseStagePending || state.LastError == "" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
+
+func TestReleaserRunInvalidPlan(t *testing.T) {
+	store := NewCacheReleaseStateStore(cache.NewMemory(), "")
+	client := &fakeReleaseClient{quota: 5}
+	r := newTestReleaser(client, store)
+	r.plan.UserDesc = ""
+	if _, err := r.Run([]string{"wx1"}); err == nil {
+		t.Fatal("plan without user_desc should be rejected")
+	}
+	if state, err := store.GetReleaseState("v1", "wx1"); err != ErrReleaseStateNotFound || client.commits != 0 {
+		t.Fatalf("invalid plan should not touch any app, state = %+v", state)
+	}
+
+	// 使用 BuildCommit 时由其生成每个小程序的参数
+	r.plan.BuildCommit = func(appid string) (CommitParam, error) {
+		return CommitParam{UserVersion: "1.0.0", UserDesc: appid}, nil
+	}
+	if _, err := r.Run([]string{"wx1"}); err != nil || client.commits != 1 {
+		t.Fatalf("run with BuildCommit: commits = %d, err = %v", client.commits, err)
+	}
+}
+
+func TestReleaserResumeAfterCrash(t *testing.T) {
+	store := NewCacheReleaseStateStore(cache.NewMemory(), "")
+	client := &fakeReleaseClient{quota: 5, status: 2, crash: true}
+	r := newTestReleaser(client, store)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("run should crash")
+			}
+		}()
+		r.Run([]string{"wx1"})
+	}()
+	// 上传和提审后立即保存，进程退出时已保存审核单
+	state, err := store.GetReleaseState("v1", "wx1")
+	if err != nil || state == nil || state.Stage != ReleaseStageAuditing || state.AuditID != 1 {
+		t.Fatalf("unexpected state %+v, err = %v", state, err)
+	}
+
+	client.crash = false
+	client.status = 0
+	report, err := r.Run([]string{"wx1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Done() || client.commits != 1 || client.submits != 1 || client.releases != 1 {
+		t.Fatalf("should resume from auditing, commits = %d, submits = %d, releases = %d", client.commits, client.submits, client.releases)
+	}
+}
+
+func TestReleaserHandleAuditEvent(t *testing.T) {
+	store := NewCacheReleaseStateStore(cache.NewMemory(), "")
+	client := &fakeReleaseClient{quota: 5, status: 2}
+	r := newTestReleaser(client, store)
+	if _, err := r.Run([]string{"wx1", "wx2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	fail := &message.MixMessage{}
+	fail.Event = message.EventWeappAuditFail
+	fail.Reason = "类目不符"
+	if err := r.HandleAuditEvent("wx1", fail); err != nil {
+		t.Fatal(err)
+	}
+	success := &message.MixMessage{}
+	success.Event = message.EventWeappAuditSuccess
+	if err := r.HandleAuditEvent("wx2", success); err != nil {
+		t.Fatal(err)
+	}
+	// 不在任务中的小程序忽略
+	if err := r.HandleAuditEvent("wx3", success); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.GetReleaseState("v1", "wx3"); err != ErrReleaseStateNotFound {
+		t.Fatalf("wx3 should not be saved, err = %v", err)
+	}
+
+	state, _ := store.GetReleaseState("v1", "wx1")
+	if state.Stage != ReleaseStageFailed || state.Reason != "类目不符" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+	state, _ = store.GetReleaseState("v1", "wx2")
+	if state.Stage != ReleaseStageAuditPassed {
+		t.Fatalf("unexpected state %+v", state)
+	}
+
+	r.plan.GrayPercentage = 10
+	report, err := r.Run([]string{"wx1", "wx2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Done() || client.gray != 10 || client.releases != 0 {
+		t.Fatalf("wx2 should be gray released, gray = %d", client.gray)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/pengshang1995/wechat-sdk/util"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -18,6 +19,18 @@ const (
 	DeleteTemplateURL = "https://api.weixin.qq.com/wxa/deletetemplate?"
 )
 
+// TemplateType 模板类型
+type TemplateType int
+
+const (
+	// TemplateTypeNormal 普通模板
+	TemplateTypeNormal TemplateType = 0
+	// TemplateTypeStandard 标准模板，需审核通过后才能使用
+	TemplateTypeStandard TemplateType = 1
+	// TemplateTypeAll 获取模板列表时不按类型过滤
+	TemplateTypeAll TemplateType = -1
+)
+
 // TplDetail 模板详情
 type TplDetail struct {
 	CreateTime             int64  `json:"create_time"`              // 1571730935
@@ -28,6 +41,19 @@ type TplDetail struct {
 	SourceMiniprogramAppid string `json:"source_miniprogram_appid"` // "wx37625cd1b23423432"
 	SourceMiniprogram      string `json:"source_miniprogram"`       // "纸喵软件"
 	Developer              string `json:"developer"`                // "小子(LT)"
+
+	TemplateType TemplateType `json:"template_type"` // 模板类型，0 普通模板 1 标准模板
+	AuditStatus  int          `json:"audit_status"`  // 标准模板的审核状态，2 审核中 3 审核通过 4 审核被拒绝
+	Reason       string       `json:"reason"`        // 标准模板审核被拒绝的原因
+}
+
+// CommitParam 使用该模板上传代码的参数，ext_json 需另行设置
+func (t TplDetail) CommitParam() CommitParam {
+	return CommitParam{
+		TemplateID:  t.TemplateID,
+		UserVersion: t.UserVersion,
+		UserDesc:    t.UserDesc,
+	}
 }
 
 // TplResponse 模板返回体
@@ -73,6 +99,134 @@ func (o *Open) TplList() (ret TplResponse, err error) {
 	return
 }
 
+// TplListByType 获取指定类型的模板列表，TemplateTypeAll 时与 TplList 相同
+func (o *Open) TplListByType(templateType TemplateType) (ret TplResponse, err error) {
+	if templateType == TemplateTypeAll {
+		return o.TplList()
+	}
+	var body []byte
+	body, err = o.get(TemplateListURL, map[string]string{
+		"template_type": strconv.Itoa(int(templateType)),
+	})
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// LatestTpl 获取指定类型中 user_version 最新的模板，版本号相同时取创建时间较晚的
+// 标准模板只在审核通过的模板中选择
+func (o *Open) LatestTpl(templateType TemplateType) (ret TplDetail, err error) {
+	list, err := o.TplListByType(templateType)
+	if err != nil {
+		return
+	}
+	latest, ok := latestTpl(list.TemplateList)
+	if !ok {
+		err = fmt.Errorf("no available template of type %d", templateType)
+		return
+	}
+	return latest, nil
+}
+
+func latestTpl(list []TplDetail) (latest TplDetail, ok bool) {
+	for _, tpl := range list {
+		// 标准模板未审核通过时不可用
+		if tpl.TemplateType == TemplateTypeStandard && tpl.AuditStatus != 3 {
+			continue
+		}
+		if !ok {
+			latest, ok = tpl, true
+			continue
+		}
+		c := compareUserVersion(tpl.UserVersion, latest.UserVersion)
+		if c > 0 || (c == 0 && tpl.CreateTime > latest.CreateTime) {
+			latest = tpl
+		}
+	}
+	return
+}
+
+// compareUserVersion 比较版本号，"-" 之后为预发布标识，带预发布标识的版本低于同号的正式版本（2.0.0-beta < 2.0.0）
+func compareUserVersion(a, b string) int {
+	aCore, aPre := splitPreRelease(a)
+	bCore, bPre := splitPreRelease(b)
+	if c := compareVersionSegments(aCore, bCore); c != 0 {
+		return c
+	}
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return compareVersionSegments(aPre, bPre)
+}
+
+func splitPreRelease(v string) (core, pre string) {
+	if i := strings.Index(v, "-"); i >= 0 {
+		return v[:i], v[i+1:]
+	}
+	return v, ""
+}
+
+// compareVersionSegments 按点分隔逐段比较，缺少的段视为 0
+// 每段先按数字前缀的数值比较（10a > 9），数字相同时不带后缀的较高（1 > 1a），否则按后缀字符串比较
+func compareVersionSegments(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xn, xs := splitNumericPrefix(x)
+		yn, ys := splitNumericPrefix(y)
+		switch {
+		case xn != yn:
+			if xn > yn {
+				return 1
+			}
+			return -1
+		case xs == ys:
+			continue
+		case xs == "":
+			return 1
+		case ys == "":
+			return -1
+		}
+		return strings.Compare(xs, ys)
+	}
+	return 0
+}
+
+// splitNumericPrefix 拆分数字前缀和其余部分，空段视为 0，没有数字前缀时数值为 -1
+func splitNumericPrefix(s string) (int, string) {
+	if s == "" {
+		return 0, ""
+	}
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return -1, s
+	}
+	n, _ := strconv.Atoi(s[:i])
+	return n, s[i:]
+}
+
 // AddDrafToTpl 添加草稿到模板
 func (o *Open) AddDrafToTpl(draftID int) (err error) {
 	body, err := o.post(AddDraftToTemplateURL, map[string]string{
@@ -92,6 +246,26 @@ func (o *Open) AddDrafToTpl(draftID int) (err error) {
 	return
 }
 
+// AddDraftToTplWithType 将草稿添加到指定类型的模板库，标准模板需等待审核
+func (o *Open) AddDraftToTplWithType(draftID int, templateType TemplateType) (err error) {
+	body, err := o.post(AddDraftToTemplateURL, map[string]int{
+		"draft_id":      draftID,
+		"template_type": int(templateType),
+	})
+	if err != nil {
+		return
+	}
+	ret := &TplResponse{}
+	err = json.Unmarshal(body, ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
 // TplDraftList 草稿列表
 func (o *Open) TplDraftList() (ret TplResponse, err error) {
 	var body []byte
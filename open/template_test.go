@@ -0,0 +1,48 @@
+package open
+
+import "testing"
+
+func TestCompareUserVersion(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.10.0", "1.9.3", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0", "1.2.1", -1},
+		{"2.0.0-beta", "2.0.0-alpha", 1},
+		{"2.0.0-beta", "2.0.0", -1},
+		{"2.0.0", "2.0.0-rc.1", 1},
+		{"2.0.0-rc.2", "2.0.0-rc.10", -1},
+		{"1.9.9", "2.0.0-alpha", -1},
+		{"10a", "9", 1},
+		{"1.2a", "1.10", -1},
+		{"1.2a", "1.2", -1},
+		{"1.2a", "1.2b", -1},
+		{"1.0.beta", "1.0.0", -1},
+	}
+	for _, c := range cases {
+		if got := compareUserVersion(c.a, c.b); got != c.want {
+			t.Errorf("compareUserVersion(%q, %q) = %d, want %d", c.a, c.b, got, c.want)
+		}
+	}
+}
+
+func TestLatestTpl(t *testing.T) {
+	list := []TplDetail{
+		{TemplateID: 1, UserVersion: "1.9.0", CreateTime: 1},
+		{TemplateID: 2, UserVersion: "1.10.0", CreateTime: 2},
+		{TemplateID: 3, UserVersion: "1.10.0", CreateTime: 3},
+		{TemplateID: 4, UserVersion: "2.0.0", CreateTime: 4, TemplateType: TemplateTypeStandard, AuditStatus: 2},
+	}
+	latest, ok := latestTpl(list)
+	if !ok || latest.TemplateID != 3 {
+		t.Fatalf("latest template = %d, want 3", latest.TemplateID)
+	}
+	if param := latest.CommitParam(); param.TemplateID != 3 || param.UserVersion != "1.10.0" {
+		t.Fatalf("unexpected commit param %+v", param)
+	}
+	if _, ok = latestTpl(list[3:]); ok {
+		t.Fatal("standard template under audit should not be picked")
+	}
+}
@@ -0,0 +1,126 @@
+package open
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+const (
+	bindTesterURL   = "https://api.weixin.qq.com/wxa/bind_tester"
+	unbindTesterURL = "https://api.weixin.qq.com/wxa/unbind_tester"
+	memberAuthURL   = "https://api.weixin.qq.com/wxa/memberauth"
+
+	// errCodeTesterAlreadyBound 微信号已经绑定为体验者
+	errCodeTesterAlreadyBound = 85004
+)
+
+// bindTesterResponse 绑定体验者返回
+type bindTesterResponse struct {
+	util.CommonError
+	UserStr string `json:"userstr"` // 人员对应的唯一字符串
+}
+
+// TesterMember 体验者
+type TesterMember struct {
+	UserStr string `json:"userstr"` // 人员对应的唯一字符串
+}
+
+// memberAuthResponse 体验者列表返回
+type memberAuthResponse struct {
+	util.CommonError
+	Members []TesterMember `json:"members"`
+}
+
+// BindTester 绑定微信用户为体验者，已绑定时不返回错误
+func (m *MiniPrograms) BindTester(wechatID string) (userStr string, err error) {
+	body, err := m.post(bindTesterURL, map[string]string{
+		"wechatid": wechatID,
+	})
+	if err != nil {
+		return
+	}
+	ret := bindTesterResponse{}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 && ret.ErrCode != errCodeTesterAlreadyBound {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	userStr = ret.UserStr
+	return
+}
+
+// UnbindTester 解除绑定体验者，wechatID 与 userStr 二选一
+func (m *MiniPrograms) UnbindTester(wechatID, userStr string) (err error) {
+	req := map[string]string{}
+	switch {
+	case userStr != "":
+		req["userstr"] = userStr
+	case wechatID != "":
+		req["wechatid"] = wechatID
+	default:
+		return fmt.Errorf("unbind tester: wechatid and userstr are both empty")
+	}
+	body, err := m.post(unbindTesterURL, req)
+	if err != nil {
+		return
+	}
+	ret := util.CommonError{}
+	err = json.Unmarshal(body, &ret)
+	if err != nil {
+		return
+	}
+	if ret.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", ret.ErrCode, ret.ErrMsg)
+	}
+	return
+}
+
+// GetTesters 获取体验者列表
+func (m *MiniPrograms) GetTesters() (ret []TesterMember, err error) {
+	body, err := m.post(memberAuthURL, map[string]string{
+		"action": "get_experiencer",
+	})
+	if err != nil {
+		return
+	}
+	resp := memberAuthResponse{}
+	err = json.Unmarshal(body, &resp)
+	if err != nil {
+		return
+	}
+	if resp.ErrCode != 0 {
+		err = fmt.Errorf("[%d]: %s", resp.ErrCode, resp.ErrMsg)
+		return
+	}
+	ret = resp.Members
+	return
+}
+
+// BindTesterResult 批量绑定体验者中单个绑定的结果
+type BindTesterResult struct {
+	AppID    string
+	WechatID string
+	UserStr  string
+	Err      error
+}
+
+// BindTesters 为多个授权小程序批量绑定同一批体验者，授权信息从 AuthorizerStore 中获取
+// 单个绑定失败不影响其它绑定，结果按 appid、wechatid 的顺序返回
+func (o *Open) BindTesters(appids []string, wechatIDs []string) []BindTesterResult {
+	results := make([]BindTesterResult, 0, len(appids)*len(wechatIDs))
+	for _, appid := range appids {
+		m, err := o.NewMiniProgramsByAppID(appid)
+		for _, wechatID := range wechatIDs {
+			result := BindTesterResult{AppID: appid, WechatID: wechatID, Err: err}
+			if err == nil {
+				result.UserStr, result.Err = m.BindTester(wechatID)
+			}
+			results = append(results, result)
+		}
+	}
+	return results
+}
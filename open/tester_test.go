@@ -0,0 +1,95 @@
+package open
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+)
+
+func TestBindTestersErrors(t *testing.T) {
+	o, store := newTestOpen(t)
+	if err := store.SaveAuthorizer(&context.Authorizer{AppID: "wx2"}); err != nil {
+		t.Fatal(err)
+	}
+	results := o.BindTesters([]string{"wx1", "wx2"}, []string{"tester1", "tester2"})
+	if len(results) != 4 {
+		t.Fatalf("len(results) = %d, want 4", len(results))
+	}
+	want := []struct{ appid, wechatID string }{{"wx1", "tester1"}, {"wx1", "tester2"}, {"wx2", "tester1"}, {"wx2", "tester2"}}
+	for i, result := range results {
+		if result.AppID != want[i].appid || result.WechatID != want[i].wechatID {
+			t.Errorf("results[%d] = %s %s, want %s %s", i, result.AppID, result.WechatID, want[i].appid, want[i].wechatID)
+		}
+		if result.Err == nil {
+			t.Errorf("results[%d] should have error", i)
+		}
+	}
+	// 未保存授权信息和缺少 refresh_token 的错误分别记录在对应小程序的结果中
+	if results[0].Err != context.ErrAuthorizerNotFound {
+		t.Errorf("wx1 err = %v, want ErrAuthorizerNotFound", results[0].Err)
+	}
+	if results[2].Err == context.ErrAuthorizerNotFound {
+		t.Errorf("wx2 err = %v, want missing refresh token", results[2].Err)
+	}
+
+	o.AuthorizerStore = nil
+	for _, result := range o.BindTesters([]string{"wx1"}, []string{"tester1"}) {
+		if result.Err == nil {
+			t.Error("bind without authorizer store should fail")
+		}
+	}
+}
+
+func TestBindTester(t *testing.T) {
+	o, _ := newTestOpen(t)
+	o.Cache.Set(o.CacheKeys().AuthorizerAccessToken("wx1"), "token", time.Hour)
+	m := o.NewMiniPrograms("wx1", "refresh")
+	var resp string
+	var gotReq map[string]string
+	stubHTTP(t, func(path string, body []byte) string {
+		gotReq = nil
+		json.Unmarshal(body, &gotReq)
+		return resp
+	})
+
+	resp = `{"errcode":0,"errmsg":"ok","userstr":"user1"}`
+	if userStr, err := m.BindTester("tester1"); err != nil || userStr != "user1" || gotReq["wechatid"] != "tester1" {
+		t.Errorf("BindTester = %s, %v, req = %v", userStr, err, gotReq)
+	}
+	// 已绑定时视为成功
+	resp = `{"errcode":85004,"errmsg":"user already bind"}`
+	if _, err := m.BindTester("tester1"); err != nil {
+		t.Errorf("already bound should succeed, err = %v", err)
+	}
+	resp = `{"errcode":85001,"errmsg":"user not exist or user cannot be searched"}`
+	if _, err := m.BindTester("tester2"); err == nil {
+		t.Error("BindTester should fail on 85001")
+	}
+}
+
+func TestUnbindTester(t *testing.T) {
+	o, _ := newTestOpen(t)
+	o.Cache.Set(o.CacheKeys().AuthorizerAccessToken("wx1"), "token", time.Hour)
+	m := o.NewMiniPrograms("wx1", "refresh")
+	var gotReq map[string]string
+	requests := 0
+	stubHTTP(t, func(path string, body []byte) string {
+		requests++
+		gotReq = nil
+		json.Unmarshal(body, &gotReq)
+		return `{"errcode":0,"errmsg":"ok"}`
+	})
+
+	// 同时传入时使用 userstr
+	if err := m.UnbindTester("tester1", "user1"); err != nil || gotReq["userstr"] != "user1" || len(gotReq) != 1 {
+		t.Errorf("UnbindTester err = %v, req = %v", err, gotReq)
+	}
+	if err := m.UnbindTester("tester1", ""); err != nil || gotReq["wechatid"] != "tester1" || len(gotReq) != 1 {
+		t.Errorf("UnbindTester err = %v, req = %v", err, gotReq)
+	}
+	if err := m.UnbindTester("", ""); err == nil || requests != 2 {
+		t.Errorf("empty arguments should be rejected without request, err = %v, requests = %d", err, requests)
+	}
+}
@@ -0,0 +1,90 @@
+package pay
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Amount 金额，单位为分
+// 微信支付接口中的金额均为整数分，使用 Amount 避免元/分换算时的浮点误差
+type Amount int64
+
+// ParseYuan 将以元为单位的金额字符串转换为 Amount，最多两位小数，如 "12.3" => 1230
+func ParseYuan(yuan string) (Amount, error) {
+	s := strings.TrimSpace(yuan)
+	if s == "" {
+		return 0, fmt.Errorf("invalid amount %q", yuan)
+	}
+	negative := false
+	if s[0] == '-' || s[0] == '+' {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+	if intPart == "" && fracPart == "" || len(fracPart) > 2 || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("invalid amount %q", yuan)
+	}
+	fracPart += strings.Repeat("0", 2-len(fracPart))
+	if intPart == "" {
+		intPart = "0"
+	}
+	fen, err := strconv.ParseInt(intPart+fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q, err=%v", yuan, err)
+	}
+	if negative {
+		fen = -fen
+	}
+	return Amount(fen), nil
+}
+
+// ParseFen 将以分为单位的金额字符串转换为 Amount
+func ParseFen(fen string) (Amount, error) {
+	v, err := strconv.ParseInt(strings.TrimSpace(fen), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q, err=%v", fen, err)
+	}
+	return Amount(v), nil
+}
+
+// Fen 以分为单位的金额
+func (a Amount) Fen() int64 {
+	return int64(a)
+}
+
+// Yuan 以元为单位的金额字符串，固定两位小数，如 1230 => "12.30"
+func (a Amount) Yuan() string {
+	fen := int64(a)
+	sign := ""
+	if fen < 0 {
+		sign = "-"
+		fen = -fen
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, fen/100, fen%100)
+}
+
+// String 以分为单位的金额字符串，与接口中传输的格式一致
+func (a Amount) String() string {
+	return strconv.FormatInt(int64(a), 10)
+}
+
+// Validate 校验金额必须大于 0
+func (a Amount) Validate(name string) error {
+	if a <= 0 {
+		return fmt.Errorf("%s must be greater than 0, got %d", name, int64(a))
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
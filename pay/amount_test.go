@@ -0,0 +1,43 @@
+package pay
+
+import "testing"
+
+func TestParseYuan(t *testing.T) {
+	cases := map[string]Amount{
+		"12":    1200,
+		"12.3":  1230,
+		"12.34": 1234,
+		"0.01":  1,
+		".5":    50,
+		"-1.05": -105,
+	}
+	for in, want := range cases {
+		got, err := ParseYuan(in)
+		if err != nil || got != want {
+			t.Errorf("ParseYuan(%q) = %d, %v, want %d", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "1.234", "abc", "1.2.3", ".", "1e3"} {
+		if _, err := ParseYuan(in); err == nil {
+			t.Errorf("ParseYuan(%q) expect error", in)
+		}
+	}
+}
+
+func TestAmountFormat(t *testing.T) {
+	if s := Amount(1230).Yuan(); s != "12.30" {
+		t.Errorf("Yuan() = %s", s)
+	}
+	if s := Amount(-5).Yuan(); s != "-0.05" {
+		t.Errorf("Yuan() = %s", s)
+	}
+	if s := Amount(1230).String(); s != "1230" {
+		t.Errorf("String() = %s", s)
+	}
+	if err := Amount(0).Validate("total_fee"); err == nil {
+		t.Error("expect error for zero amount")
+	}
+	if err := Amount(-1).Validate("total_fee"); err == nil {
+		t.Error("expect error for negative amount")
+	}
+}
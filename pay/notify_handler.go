@@ -0,0 +1,310 @@
+package pay
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+// maxNotifyBodySize 通知请求体的最大长度
+const maxNotifyBodySize = 64 << 10
+
+// NotifyOrder 业务系统中的订单，用于校验通知内容是否与下单时一致
+type NotifyOrder struct {
+	AppID    string // 下单时的 appid，为空时使用配置中的 appid
+	MchID    string // 下单时的商户号，为空时使用配置中的商户号
+	TotalFee Amount // 订单金额
+	Handled  bool   // 该通知对应的业务是否已处理，已处理时直接应答成功，不再回调业务
+}
+
+// NotifyOrderLookup 根据通知内容查找业务订单，退款通知可通过 OutRefundNo 区分退款单
+// 返回 nil 表示订单不存在
+type NotifyOrderLookup func(result *NotifyResult) (*NotifyOrder, error)
+
+// NotifyCallback 业务处理回调，返回错误时应答 FAIL，微信会重新通知
+type NotifyCallback func(result *NotifyResult) error
+
+// NotifyHandler 支付结果通知及退款结果通知的 http.Handler
+// 处理流程：验签/解密 => 查找订单并校验 appid、mch_id、金额 => 回调业务 => 应答 SUCCESS/FAIL
+// 同一订单（退款通知为同一退款单）的重复通知在进程内串行处理，回调成功后 lookup 需返回 Handled，
+// 后续的重复通知才不会再次回调；多实例部署时重复通知可能同时到达不同实例，回调需自行保证幂等
+type NotifyHandler struct {
+	pay      *Pay
+	lookup   NotifyOrderLookup
+	onPay    NotifyCallback
+	onRefund NotifyCallback
+
+	mu    sync.Mutex
+	locks map[string]*notifyLock
+}
+
+// notifyLock 单个订单的锁，没有等待者时删除
+type notifyLock struct {
+	sync.Mutex
+	refs int
+}
+
+// NewNotifyHandler 创建支付通知处理器
+func (pcf *Pay) NewNotifyHandler(lookup NotifyOrderLookup) *NotifyHandler {
+	return &NotifyHandler{
+		pay:    pcf,
+		lookup: lookup,
+	}
+}
+
+// OnPay 设置支付成功通知的业务回调，只在 return_code 和 result_code 均为 SUCCESS 时回调
+func (h *NotifyHandler) OnPay(callback NotifyCallback) *NotifyHandler {
+	h.onPay = callback
+	return h
+}
+
+// OnRefund 设置退款成功通知的业务回调，只在 refund_status 为 SUCCESS 时回调
+// 退款异常（CHANGE）和退款关闭（REFUNDCLOSE）的通知直接应答成功，需通过退款查询接口处理
+func (h *NotifyHandler) OnRefund(callback NotifyCallback) *NotifyHandler {
+	h.onRefund = callback
+	return h
+}
+
+// ServeHTTP 实现 http.Handler
+func (h *NotifyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := h.handle(http.MaxBytesReader(w, r.Body, maxNotifyBodySize)); err != nil {
+		writeNotifyResp(w, "FAIL", err.Error())
+		return
+	}
+	writeNotifyResp(w, "SUCCESS", "OK")
+}
+
+func (h *NotifyHandler) handle(body io.Reader) error {
+	raw, err := ioutil.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("read body error, err=%v", err)
+	}
+	result, err := h.pay.ParseNotify(raw)
+	if err != nil {
+		return err
+	}
+	// 支付失败或退款未成功的通知只应答，不回调业务
+	if !result.succeeded() {
+		return nil
+	}
+	if h.lookup == nil {
+		return fmt.Errorf("order lookup is not set")
+	}
+	unlock := h.lock(result.notifyKey())
+	defer unlock()
+	order, err := h.lookup(result)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		return fmt.Errorf("order %s not found", result.OutTradeNo)
+	}
+	if err = h.checkOrder(result, order); err != nil {
+		return err
+	}
+	if order.Handled {
+		return nil
+	}
+	callback := h.onPay
+	if result.PayNotifyInfo == PayTypeRefund {
+		callback = h.onRefund
+	}
+	if callback == nil {
+		return nil
+	}
+	return callback(result)
+}
+
+// lock 锁定订单，返回解锁函数
+func (h *NotifyHandler) lock(key string) func() {
+	h.mu.Lock()
+	if h.locks == nil {
+		h.locks = make(map[string]*notifyLock)
+	}
+	l := h.locks[key]
+	if l == nil {
+		l = new(notifyLock)
+		h.locks[key] = l
+	}
+	l.refs++
+	h.mu.Unlock()
+
+	l.Lock()
+	return func() {
+		l.Unlock()
+		h.mu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(h.locks, key)
+		}
+		h.mu.Unlock()
+	}
+}
+
+// notifyKey 通知对应的业务单号，退款通知为退款单号
+func (result *NotifyResult) notifyKey() string {
+	if result.PayNotifyInfo == PayTypeRefund {
+		return "refund:" + result.OutRefundNo
+	}
+	return "pay:" + result.OutTradeNo
+}
+
+// succeeded 支付通知要求 result_code 为 SUCCESS，退款通知要求 refund_status 为 SUCCESS
+func (result *NotifyResult) succeeded() bool {
+	if result.ReturnCode != "SUCCESS" {
+		return false
+	}
+	if result.PayNotifyInfo == PayTypeRefund {
+		return result.RefundStatus == "SUCCESS"
+	}
+	return result.ResultCode == "SUCCESS"
+}
+
+func (h *NotifyHandler) checkOrder(result *NotifyResult, order *NotifyOrder) error {
+	appID, mchID := order.AppID, order.MchID
+	if appID == "" {
+		appID = h.pay.AppID
+	}
+	if mchID == "" {
+		mchID = h.pay.PayMchID
+	}
+	if result.AppID != appID {
+		return fmt.Errorf("appid mismatch, notify=%s", result.AppID)
+	}
+	if result.MchID != mchID {
+		return fmt.Errorf("mch_id mismatch, notify=%s", result.MchID)
+	}
+	if result.TotalFee != order.TotalFee {
+		return fmt.Errorf("total_fee mismatch, notify=%s, order=%s", result.TotalFee.Yuan(), order.TotalFee.Yuan())
+	}
+	return nil
+}
+
+// ParseNotify 解析支付/退款结果通知
+// 支付通知校验签名（支持 MD5 和 HMAC-SHA256），退款通知解密 req_info
+func (pcf *Pay) ParseNotify(raw []byte) (result *NotifyResult, err error) {
+	params, err := parseNotifyXML(raw)
+	if err != nil {
+		return
+	}
+	if params["return_code"] != "SUCCESS" {
+		err = fmt.Errorf("notify return_code=%s, return_msg=%s", params["return_code"], params["return_msg"])
+		return
+	}
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	result = new(NotifyResult)
+	if err = xml.Unmarshal(raw, result); err != nil {
+		return
+	}
+	if result.ReqInfo == "" {
+		if !VerifyNotifySign(params, payKey) {
+			err = fmt.Errorf("notify sign invalid")
+			return
+		}
+		result.PayNotifyInfo = PayTypePay
+		return
+	}
+	// 退款通知没有签名，能用商户密钥解密即认为合法
+	var plaintext []byte
+	plaintext, err = decryptReqInfo(result.ReqInfo, payKey)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(plaintext, result); err != nil {
+		return
+	}
+	result.PayNotifyInfo = PayTypeRefund
+	return
+}
+
+// VerifyNotifySign 对通知中的全部字段验签，签名类型取 sign_type，未传时依次尝试 MD5 和 HMAC-SHA256
+func VerifyNotifySign(params map[string]string, payKey string) bool {
+	sign := params["sign"]
+	if sign == "" {
+		return false
+	}
+	str := orderParam(params, "&key="+payKey)
+	switch params["sign_type"] {
+	case SignTypeMD5, SignTypeHMACSHA256:
+		return makeSign(str, params["sign_type"], payKey) == sign
+	}
+	return makeSign(str, SignTypeMD5, payKey) == sign || makeSign(str, SignTypeHMACSHA256, payKey) == sign
+}
+
+// decryptReqInfo 解密退款通知的 req_info，AES-256-ECB，密钥为商户密钥的 md5
+func decryptReqInfo(reqInfo, payKey string) (plaintext []byte, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("decrypt req_info error: %v", e)
+		}
+	}()
+	encryptData, err := base64.StdEncoding.DecodeString(reqInfo)
+	if err != nil {
+		return
+	}
+	if len(encryptData) == 0 || len(encryptData)%aes.BlockSize != 0 {
+		err = fmt.Errorf("req_info is not a multiple of the block size")
+		return
+	}
+	plaintext, err = util.ECBDecrypt(encryptData, []byte(util.MD5(payKey)))
+	if err == nil && len(plaintext) == 0 {
+		err = fmt.Errorf("req_info is empty after decrypt")
+	}
+	return
+}
+
+// parseNotifyXML 将通知的一级节点解析为 map
+func parseNotifyXML(raw []byte) (map[string]string, error) {
+	params := make(map[string]string)
+	decoder := xml.NewDecoder(bytes.NewReader(raw))
+	var (
+		key   string
+		depth int
+		value strings.Builder
+	)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse notify xml error, err=%v", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			depth++
+			if depth == 2 {
+				key = t.Name.Local
+				value.Reset()
+			}
+		case xml.CharData:
+			if depth == 2 {
+				value.Write(t)
+			}
+		case xml.EndElement:
+			if depth == 2 {
+				params[key] = value.String()
+			}
+			depth--
+		}
+	}
+	return params, nil
+}
+
+func writeNotifyResp(w http.ResponseWriter, code, msg string) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	data, _ := xml.Marshal(NotifyResp{ReturnCode: code, ReturnMsg: msg})
+	w.Write(data)
+}
@@ -0,0 +1,122 @@
+package pay
+
+import (
+	"bytes"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+)
+
+func buildNotify(t *testing.T, payKey, signType, totalFee, resultCode string) string {
+	t.Helper()
+	params := map[string]string{
+		"return_code":    "SUCCESS",
+		"result_code":    resultCode,
+		"appid":          "wx123",
+		"mch_id":         "10000",
+		"nonce_str":      "abc",
+		"out_trade_no":   "order1",
+		"transaction_id": "tx1",
+		"total_fee":      totalFee,
+		"sign_type":      signType,
+	}
+	sign := makeSign(orderParam(params, "&key="+payKey), signType, payKey)
+	var buf bytes.Buffer
+	buf.WriteString("<xml>")
+	for k, v := range params {
+		fmt.Fprintf(&buf, "<%s><![CDATA[%s]]></%s>", k, v, k)
+	}
+	fmt.Fprintf(&buf, "<sign>%s</sign></xml>", sign)
+	return buf.String()
+}
+
+func TestNotifyHandler(t *testing.T) {
+	pcf := NewPay(&context.Context{AppID: "wx123", PayMchID: "10000", PayKey: "key"})
+	var calls int
+	handled := false
+	h := pcf.NewNotifyHandler(func(result *NotifyResult) (*NotifyOrder, error) {
+		return &NotifyOrder{TotalFee: 100, Handled: handled}, nil
+	}).OnPay(func(result *NotifyResult) error {
+		calls++
+		return nil
+	})
+
+	serve := func(body string) string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("POST", "/notify", strings.NewReader(body)))
+		return w.Body.String()
+	}
+
+	for _, signType := range []string{SignTypeMD5, SignTypeHMACSHA256} {
+		if rsp := serve(buildNotify(t, "key", signType, "100", "SUCCESS")); !strings.Contains(rsp, "SUCCESS") {
+			t.Errorf("%s notify expect SUCCESS, got %s", signType, rsp)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("callback calls = %d, want 2", calls)
+	}
+	if rsp := serve(buildNotify(t, "wrong", SignTypeMD5, "100", "SUCCESS")); !strings.Contains(rsp, "FAIL") {
+		t.Errorf("bad sign expect FAIL, got %s", rsp)
+	}
+	if rsp := serve(buildNotify(t, "key", SignTypeMD5, "1", "SUCCESS")); !strings.Contains(rsp, "FAIL") {
+		t.Errorf("amount mismatch expect FAIL, got %s", rsp)
+	}
+	if rsp := serve(buildNotify(t, "key", SignTypeMD5, "100", "FAIL")); !strings.Contains(rsp, "SUCCESS") || calls != 2 {
+		t.Errorf("failed payment expect SUCCESS without callback, got %s, calls=%d", rsp, calls)
+	}
+	if rsp := serve(strings.Repeat("x", maxNotifyBodySize+1)); !strings.Contains(rsp, "FAIL") {
+		t.Errorf("oversized body expect FAIL, got %s", rsp)
+	}
+	handled = true
+	if rsp := serve(buildNotify(t, "key", SignTypeMD5, "100", "SUCCESS")); !strings.Contains(rsp, "SUCCESS") || calls != 2 {
+		t.Errorf("handled notify expect SUCCESS without callback, got %s, calls=%d", rsp, calls)
+	}
+}
+
+func TestNotifyHandlerConcurrentDuplicates(t *testing.T) {
+	pcf := NewPay(&context.Context{AppID: "wx123", PayMchID: "10000", PayKey: "key"})
+	var (
+		mu      sync.Mutex
+		handled bool
+		calls   int32
+	)
+	h := pcf.NewNotifyHandler(func(result *NotifyResult) (*NotifyOrder, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return &NotifyOrder{TotalFee: 100, Handled: handled}, nil
+	}).OnPay(func(result *NotifyResult) error {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		handled = true
+		mu.Unlock()
+		return nil
+	})
+
+	body := buildNotify(t, "key", SignTypeMD5, "100", "SUCCESS")
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest("POST", "/notify", strings.NewReader(body)))
+			if !strings.Contains(w.Body.String(), "SUCCESS") {
+				t.Errorf("expect SUCCESS, got %s", w.Body.String())
+			}
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("callback calls = %d, want 1", calls)
+	}
+	if len(h.locks) != 0 {
+		t.Errorf("locks should be released, got %d", len(h.locks))
+	}
+}
@@ -1,6 +1,7 @@
 package pay
 
 import (
+	"encoding/xml"
 	"fmt"
 	"github.com/pengshang1995/wechat-sdk/util"
 	"sort"
@@ -24,6 +25,8 @@ type Base struct {
 
 	AppID    string `xml:"appid"`
 	MchID    string `xml:"mch_id"`
+	SubAppID string `xml:"sub_appid"`  // 服务商模式下的子商户公众账号ID
+	SubMchID string `xml:"sub_mch_id"` // 服务商模式下的子商户号
 	NonceStr string `xml:"nonce_str"`
 	Sign     string `xml:"sign"`
 	ReqInfo  string `xml:"req_info"` // 退款加密数据
@@ -33,27 +36,29 @@ type Base struct {
 type NotifyResult struct {
 	Base
 	OutTradeNo    string `xml:"out_trade_no"`   // 商户订单号
-	TotalFee      int    `xml:"total_fee"`      // 订单金额
+	TotalFee      Amount `xml:"total_fee"`      // 订单金额
 	TransactionId string `xml:"transaction_id"` // 微信订单号
 
 	// 支付成功回调
-	ResultCode  string `xml:"result_code"`
-	OpenID      string `xml:"openid"`
-	IsSubscribe string `xml:"is_subscribe"`
-	TradeType   string `xml:"trade_type"`
-	BankType    string `xml:"bank_type"`
-	FeeType     string `xml:"fee_type"`
-	CashFee     int    `xml:"cash_fee"`
-	CashFeeType string `xml:"cash_fee_type"`
-	Attach      string `xml:"attach"`
-	TimeEnd     string `xml:"time_end"`
+	ResultCode     string `xml:"result_code"`
+	OpenID         string `xml:"openid"`
+	IsSubscribe    string `xml:"is_subscribe"`
+	SubOpenID      string `xml:"sub_openid"`       // 服务商模式下用户在子商户 appid 下的标识
+	SubIsSubscribe string `xml:"sub_is_subscribe"` // 服务商模式下用户是否关注子公众账号
+	TradeType      string `xml:"trade_type"`
+	BankType       string `xml:"bank_type"`
+	FeeType        string `xml:"fee_type"`
+	CashFee        Amount `xml:"cash_fee"`
+	CashFeeType    string `xml:"cash_fee_type"`
+	Attach         string `xml:"attach"`
+	TimeEnd        string `xml:"time_end"`
 
 	// 退款相关
 	RefundId            string `xml:"refund_id"`             // 微信退款单号
 	OutRefundNo         string `xml:"out_refund_no"`         // 商户退款单号
-	SettlementTotalFee  int    `xml:"settlement_total_fee"`  // 应结订单金额 当该订单有使用非充值券时，返回此字段。应结订单金额=订单金额-非充值代金券金额，应结订单金额<=订单金额。
-	RefundFee           int    `xml:"refund_fee"`            // 申请退款金额
-	SettlementRefundFee int    `xml:"settlement_refund_fee"` // 退款金额
+	SettlementTotalFee  Amount `xml:"settlement_total_fee"`  // 应结订单金额 当该订单有使用非充值券时，返回此字段。应结订单金额=订单金额-非充值代金券金额，应结订单金额<=订单金额。
+	RefundFee           Amount `xml:"refund_fee"`            // 申请退款金额
+	SettlementRefundFee Amount `xml:"settlement_refund_fee"` // 退款金额
 	RefundStatus        string `xml:"refund_status"`         // 退款状态 SUCCESS-退款成功 CHANGE-退款异常 REFUNDCLOSE—退款关闭
 	SuccessTime         string `xml:"success_time"`          // 退款成功时间
 	RefundRecvAccout    string `xml:"refund_recv_accout"`    // 退款入账账户
@@ -63,8 +68,9 @@ type NotifyResult struct {
 
 // NotifyResp 消息通知返回
 type NotifyResp struct {
-	ReturnCode string `xml:"return_code"`
-	ReturnMsg  string `xml:"return_msg"`
+	XMLName    xml.Name `xml:"xml"`
+	ReturnCode string   `xml:"return_code"`
+	ReturnMsg  string   `xml:"return_msg"`
 }
 
 // VerifySign 验签
@@ -74,12 +80,16 @@ func VerifySign(payKey string, notifyRes NotifyResult) bool {
 	// base
 	resMap["appid"] = notifyRes.AppID
 	resMap["mch_id"] = notifyRes.MchID
+	resMap["sub_appid"] = notifyRes.SubAppID
+	resMap["sub_mch_id"] = notifyRes.SubMchID
 	resMap["nonce_str"] = notifyRes.NonceStr
 	// NotifyResult
 	resMap["return_code"] = notifyRes.ReturnCode
 	resMap["result_code"] = notifyRes.ResultCode
 	resMap["openid"] = notifyRes.OpenID
 	resMap["is_subscribe"] = notifyRes.IsSubscribe
+	resMap["sub_openid"] = notifyRes.SubOpenID
+	resMap["sub_is_subscribe"] = notifyRes.SubIsSubscribe
 	resMap["trade_type"] = notifyRes.TradeType
 	resMap["bank_type"] = notifyRes.BankType
 	resMap["total_fee"] = notifyRes.TotalFee
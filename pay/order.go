@@ -0,0 +1,197 @@
+package pay
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+var (
+	queryOrderGateway = "https://api.mch.weixin.qq.com/pay/orderquery"
+	closeOrderGateway = "https://api.mch.weixin.qq.com/pay/closeorder"
+)
+
+// QueryOrderParams 查询订单参数，TransactionID 和 OutTradeNo 二选一
+type QueryOrderParams struct {
+	TransactionID string
+	OutTradeNo    string
+}
+
+// queryOrderRequest 查询订单接口请求参数
+type queryOrderRequest struct {
+	AppID         string `xml:"appid"`
+	MchID         string `xml:"mch_id"`
+	SubAppID      string `xml:"sub_appid,omitempty"`
+	SubMchID      string `xml:"sub_mch_id,omitempty"`
+	TransactionID string `xml:"transaction_id,omitempty"`
+	OutTradeNo    string `xml:"out_trade_no,omitempty"`
+	NonceStr      string `xml:"nonce_str"`
+	Sign          string `xml:"sign"`
+	SignType      string `xml:"sign_type,omitempty"`
+}
+
+// QueryOrderResponse 查询订单接口返回
+type QueryOrderResponse struct {
+	ReturnCode         string `xml:"return_code"`
+	ReturnMsg          string `xml:"return_msg"`
+	AppID              string `xml:"appid,omitempty"`
+	MchID              string `xml:"mch_id,omitempty"`
+	SubAppID           string `xml:"sub_appid,omitempty"`
+	SubMchID           string `xml:"sub_mch_id,omitempty"`
+	NonceStr           string `xml:"nonce_str,omitempty"`
+	Sign               string `xml:"sign,omitempty"`
+	ResultCode         string `xml:"result_code,omitempty"`
+	ErrCode            string `xml:"err_code,omitempty"`
+	ErrCodeDes         string `xml:"err_code_des,omitempty"`
+	DeviceInfo         string `xml:"device_info,omitempty"`
+	OpenID             string `xml:"openid,omitempty"`
+	IsSubscribe        string `xml:"is_subscribe,omitempty"`
+	SubOpenID          string `xml:"sub_openid,omitempty"`
+	SubIsSubscribe     string `xml:"sub_is_subscribe,omitempty"`
+	TradeType          string `xml:"trade_type,omitempty"`
+	TradeState         string `xml:"trade_state,omitempty"` // SUCCESS REFUND NOTPAY CLOSED REVOKED USERPAYING PAYERROR
+	BankType           string `xml:"bank_type,omitempty"`
+	TotalFee           Amount `xml:"total_fee,omitempty"`
+	SettlementTotalFee Amount `xml:"settlement_total_fee,omitempty"`
+	FeeType            string `xml:"fee_type,omitempty"`
+	CashFee            Amount `xml:"cash_fee,omitempty"`
+	CashFeeType        string `xml:"cash_fee_type,omitempty"`
+	TransactionID      string `xml:"transaction_id,omitempty"`
+	OutTradeNo         string `xml:"out_trade_no,omitempty"`
+	Attach             string `xml:"attach,omitempty"`
+	TimeEnd            string `xml:"time_end,omitempty"`
+	TradeStateDesc     string `xml:"trade_state_desc,omitempty"`
+}
+
+// closeOrderRequest 关闭订单接口请求参数
+type closeOrderRequest struct {
+	AppID      string `xml:"appid"`
+	MchID      string `xml:"mch_id"`
+	SubAppID   string `xml:"sub_appid,omitempty"`
+	SubMchID   string `xml:"sub_mch_id,omitempty"`
+	OutTradeNo string `xml:"out_trade_no"`
+	NonceStr   string `xml:"nonce_str"`
+	Sign       string `xml:"sign"`
+	SignType   string `xml:"sign_type,omitempty"`
+}
+
+// CloseOrderResponse 关闭订单接口返回
+type CloseOrderResponse struct {
+	ReturnCode string `xml:"return_code"`
+	ReturnMsg  string `xml:"return_msg"`
+	AppID      string `xml:"appid,omitempty"`
+	MchID      string `xml:"mch_id,omitempty"`
+	SubAppID   string `xml:"sub_appid,omitempty"`
+	SubMchID   string `xml:"sub_mch_id,omitempty"`
+	NonceStr   string `xml:"nonce_str,omitempty"`
+	Sign       string `xml:"sign,omitempty"`
+	ResultCode string `xml:"result_code,omitempty"`
+	ResultMsg  string `xml:"result_msg,omitempty"`
+	ErrCode    string `xml:"err_code,omitempty"`
+	ErrCodeDes string `xml:"err_code_des,omitempty"`
+}
+
+// QueryOrder 查询订单
+func (pcf *Pay) QueryOrder(p *QueryOrderParams) (rsp QueryOrderResponse, err error) {
+	if p.TransactionID == "" && p.OutTradeNo == "" {
+		err = fmt.Errorf("transaction_id and out_trade_no cannot both be empty")
+		return
+	}
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["appid"] = pcf.AppID
+	param["mch_id"] = pcf.PayMchID
+	param["nonce_str"] = nonceStr
+	param["transaction_id"] = p.TransactionID
+	param["out_trade_no"] = p.OutTradeNo
+	param["sign_type"] = "MD5"
+	pcf.setSubMerchantParam(param)
+
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
+	str := orderParam(param, bizKey)
+	sign := util.MD5Sum(str)
+	subAppID, subMchID := pcf.subMerchantInfo()
+	request := queryOrderRequest{
+		AppID:         pcf.AppID,
+		MchID:         pcf.PayMchID,
+		SubAppID:      subAppID,
+		SubMchID:      subMchID,
+		TransactionID: p.TransactionID,
+		OutTradeNo:    p.OutTradeNo,
+		NonceStr:      nonceStr,
+		Sign:          sign,
+		SignType:      "MD5",
+	}
+	rawRet, err := util.PostXML(pcf.gateway(queryOrderGateway), request)
+	if err != nil {
+		return
+	}
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode == "SUCCESS" {
+		if rsp.ResultCode == "SUCCESS" {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("query order error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		return
+	}
+	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	return
+}
+
+// CloseOrder 关闭订单
+func (pcf *Pay) CloseOrder(outTradeNo string) (rsp CloseOrderResponse, err error) {
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["appid"] = pcf.AppID
+	param["mch_id"] = pcf.PayMchID
+	param["nonce_str"] = nonceStr
+	param["out_trade_no"] = outTradeNo
+	param["sign_type"] = "MD5"
+	pcf.setSubMerchantParam(param)
+
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
+	str := orderParam(param, bizKey)
+	sign := util.MD5Sum(str)
+	subAppID, subMchID := pcf.subMerchantInfo()
+	request := closeOrderRequest{
+		AppID:      pcf.AppID,
+		MchID:      pcf.PayMchID,
+		SubAppID:   subAppID,
+		SubMchID:   subMchID,
+		OutTradeNo: outTradeNo,
+		NonceStr:   nonceStr,
+		Sign:       sign,
+		SignType:   "MD5",
+	}
+	rawRet, err := util.PostXML(pcf.gateway(closeOrderGateway), request)
+	if err != nil {
+		return
+	}
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode == "SUCCESS" {
+		if rsp.ResultCode == "SUCCESS" {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("close order error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		return
+	}
+	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	return
+}
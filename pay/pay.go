@@ -20,19 +20,36 @@ import (
 
 var payGateway = "https://api.mch.weixin.qq.com/pay/unifiedorder"
 
+const (
+	// SignTypeMD5 MD5 签名
+	SignTypeMD5 = "MD5"
+	// SignTypeHMACSHA256 HMAC-SHA256 签名
+	SignTypeHMACSHA256 = "HMAC-SHA256"
+)
+
 // Pay struct extends context
 type Pay struct {
 	*context.Context
+
+	subMerchant *SubMerchant // 服务商模式下的子商户
+	sandbox     bool         // 仿真测试模式，创建时取自 Config.PaySandbox
+}
+
+// SubMerchant 服务商模式下的子商户信息
+type SubMerchant struct {
+	SubAppID string // 子商户公众账号ID，不传时使用服务商的 appid 下单
+	SubMchID string // 子商户号
 }
 
 // Params was NEEDED when request unifiedorder
 // 传入的参数，用于生成 prepay_id 的必需参数
 type Params struct {
-	TotalFee   string
+	TotalFee   Amount
 	CreateIP   string
 	Body       string
 	OutTradeNo string
 	OpenID     string
+	SubOpenID  string // 服务商模式下用户在子商户 appid 下的唯一标识
 	TradeType  string
 	SignType   string
 	Detail     string
@@ -57,6 +74,8 @@ type PreOrder struct {
 	ReturnMsg  string `xml:"return_msg"`
 	AppID      string `xml:"appid,omitempty"`
 	MchID      string `xml:"mch_id,omitempty"`
+	SubAppID   string `xml:"sub_appid,omitempty"`
+	SubMchID   string `xml:"sub_mch_id,omitempty"`
 	NonceStr   string `xml:"nonce_str,omitempty"`
 	Sign       string `xml:"sign,omitempty"`
 	ResultCode string `xml:"result_code,omitempty"`
@@ -71,6 +90,8 @@ type PreOrder struct {
 type payRequest struct {
 	AppID          string `xml:"appid"`
 	MchID          string `xml:"mch_id"`
+	SubAppID       string `xml:"sub_appid,omitempty"`  // 子商户公众账号ID
+	SubMchID       string `xml:"sub_mch_id,omitempty"` // 子商户号
 	DeviceInfo     string `xml:"device_info,omitempty"`
 	NonceStr       string `xml:"nonce_str"`
 	Sign           string `xml:"sign"`
@@ -80,7 +101,7 @@ type payRequest struct {
 	Attach         string `xml:"attach,omitempty"`      // 附加数据
 	OutTradeNo     string `xml:"out_trade_no"`          // 商户订单号
 	FeeType        string `xml:"fee_type,omitempty"`    // 标价币种
-	TotalFee       string `xml:"total_fee"`             // 标价金额
+	TotalFee       Amount `xml:"total_fee"`             // 标价金额
 	SpbillCreateIP string `xml:"spbill_create_ip"`      // 终端IP
 	TimeStart      string `xml:"time_start,omitempty"`  // 交易起始时间
 	TimeExpire     string `xml:"time_expire,omitempty"` // 交易结束时间
@@ -90,15 +111,46 @@ type payRequest struct {
 	ProductID      string `xml:"product_id,omitempty"`  // 商品ID
 	LimitPay       string `xml:"limit_pay,omitempty"`   //
 	OpenID         string `xml:"openid,omitempty"`      // 用户标识
+	SubOpenID      string `xml:"sub_openid,omitempty"`  // 用户子标识
 	SceneInfo      string `xml:"scene_info,omitempty"`  // 场景信息
 }
 
 // NewPay return an instance of Pay package
 func NewPay(ctx *context.Context) *Pay {
-	pay := Pay{Context: ctx}
+	pay := Pay{Context: ctx, sandbox: ctx.PaySandbox}
 	return &pay
 }
 
+// WithSubMerchant 以服务商身份代子商户调用支付接口
+// 返回的实例与原实例共享配置，仅对本次返回的实例生效，可按次调用切换子商户
+func (pcf *Pay) WithSubMerchant(sub SubMerchant) *Pay {
+	return &Pay{
+		Context:     pcf.Context,
+		subMerchant: &sub,
+		sandbox:     pcf.sandbox,
+	}
+}
+
+// IsServiceProvider 是否为服务商模式
+func (pcf *Pay) IsServiceProvider() bool {
+	return pcf.subMerchant != nil && pcf.subMerchant.SubMchID != ""
+}
+
+// subMerchantInfo 返回当前子商户信息，非服务商模式时为空
+func (pcf *Pay) subMerchantInfo() (subAppID, subMchID string) {
+	if !pcf.IsServiceProvider() {
+		return
+	}
+	return pcf.subMerchant.SubAppID, pcf.subMerchant.SubMchID
+}
+
+// setSubMerchantParam 服务商模式下往签名参数中加入子商户信息
+func (pcf *Pay) setSubMerchantParam(param map[string]interface{}) {
+	subAppID, subMchID := pcf.subMerchantInfo()
+	param["sub_appid"] = subAppID
+	param["sub_mch_id"] = subMchID
+}
+
 // BridgeConfig get js bridge config
 func (pcf *Pay) BridgeConfig(p *Params) (cfg Config, err error) {
 	var (
@@ -110,8 +162,17 @@ func (pcf *Pay) BridgeConfig(p *Params) (cfg Config, err error) {
 	if err != nil {
 		return
 	}
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	// 服务商模式下使用子商户 appid 调起支付
+	appID := order.AppID
+	if p.SubOpenID != "" && order.SubAppID != "" {
+		appID = order.SubAppID
+	}
 	buffer.WriteString("appId=")
-	buffer.WriteString(order.AppID)
+	buffer.WriteString(appID)
 	buffer.WriteString("&nonceStr=")
 	buffer.WriteString(order.NonceStr)
 	buffer.WriteString("&package=")
@@ -121,11 +182,11 @@ func (pcf *Pay) BridgeConfig(p *Params) (cfg Config, err error) {
 	buffer.WriteString("&timeStamp=")
 	buffer.WriteString(timestamp)
 	buffer.WriteString("&key=")
-	buffer.WriteString(pcf.PayKey)
+	buffer.WriteString(payKey)
 	if p.SignType == "MD5" {
 		h = md5.New()
 	} else {
-		h = hmac.New(sha256.New, []byte(pcf.PayKey))
+		h = hmac.New(sha256.New, []byte(payKey))
 	}
 	h.Write([]byte(buffer.String()))
 	// 签名
@@ -140,6 +201,9 @@ func (pcf *Pay) BridgeConfig(p *Params) (cfg Config, err error) {
 
 // PrePayOrder return data for invoke wechat payment
 func (pcf *Pay) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
+	if err = p.TotalFee.Validate("total_fee"); err != nil {
+		return
+	}
 	nonceStr := util.RandomStr(32)
 	notifyURL := pcf.PayNotifyURL
 	// 签名类型
@@ -160,18 +224,27 @@ func (pcf *Pay) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
 	param["total_fee"] = p.TotalFee
 	param["trade_type"] = p.TradeType
 	param["openid"] = p.OpenID
+	param["sub_openid"] = p.SubOpenID
 	param["sign_type"] = p.SignType
 	param["detail"] = p.Detail
 	param["attach"] = p.Attach
 	param["goods_tag"] = p.GoodsTag
 	param["notify_url"] = notifyURL
+	pcf.setSubMerchantParam(param)
 
-	bizKey := "&key=" + pcf.PayKey
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
 	str := orderParam(param, bizKey)
 	sign := util.MD5Sum(str)
+	subAppID, subMchID := pcf.subMerchantInfo()
 	request := payRequest{
 		AppID:          pcf.AppID,
 		MchID:          pcf.PayMchID,
+		SubAppID:       subAppID,
+		SubMchID:       subMchID,
 		NonceStr:       nonceStr,
 		Sign:           sign,
 		Body:           p.Body,
@@ -181,12 +254,13 @@ func (pcf *Pay) PrePayOrder(p *Params) (payOrder PreOrder, err error) {
 		NotifyURL:      notifyURL,
 		TradeType:      p.TradeType,
 		OpenID:         p.OpenID,
+		SubOpenID:      p.SubOpenID,
 		SignType:       p.SignType,
 		Detail:         p.Detail,
 		Attach:         p.Attach,
 		GoodsTag:       p.GoodsTag,
 	}
-	rawRet, err := util.PostXML(payGateway, request)
+	rawRet, err := util.PostXML(pcf.gateway(payGateway), request)
 	if err != nil {
 		return
 	}
@@ -220,6 +294,25 @@ func (pcf *Pay) PrePayID(p *Params) (prePayID string, err error) {
 	return
 }
 
+// postXMLWithCert 使用商户证书请求接口，复用 Context 中缓存的 TLS 客户端
+func (pcf *Pay) postXMLWithCert(uri string, obj interface{}) ([]byte, error) {
+	client, err := pcf.GetPayTLSClient()
+	if err != nil {
+		return nil, err
+	}
+	return util.PostXMLWithClient(client, pcf.gateway(uri), obj)
+}
+
+// makeSign 按签名类型对 orderParam 拼接好的字符串签名
+func makeSign(str, signType, key string) string {
+	if signType == SignTypeHMACSHA256 {
+		h := hmac.New(sha256.New, []byte(key))
+		h.Write([]byte(str))
+		return strings.ToUpper(hex.EncodeToString(h.Sum(nil)))
+	}
+	return util.MD5Sum(str)
+}
+
 // order params
 func orderParam(source interface{}, bizKey string) (returnStr string) {
 	switch v := source.(type) {
@@ -270,6 +363,8 @@ func orderParam(source interface{}, bizKey string) (returnStr string) {
 				buf.WriteString(vv)
 			case int:
 				buf.WriteString(strconv.FormatInt(int64(vv), 10))
+			case Amount:
+				buf.WriteString(vv.String())
 			default:
 				panic("params type not supported")
 			}
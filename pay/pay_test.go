@@ -0,0 +1,92 @@
+package pay
+
+import (
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+)
+
+const (
+	testPayKey   = "192006250b4c09247ec02edce69f6a2d"
+	testNonceStr = "5K8264ILTKCH16CQ2502SI8ZNMTM67VS"
+)
+
+type signCase struct {
+	name     string
+	param    map[string]interface{}
+	signType string
+	want     string // 签名串，不含 &key=
+	wantSign string
+}
+
+func checkSign(t *testing.T, cases []signCase) {
+	t.Helper()
+	for _, c := range cases {
+		str := orderParam(c.param, "&key="+testPayKey)
+		if str != c.want+"&key="+testPayKey {
+			t.Errorf("%s: orderParam = %s, want %s", c.name, str, c.want)
+		}
+		if sign := makeSign(str, c.signType, testPayKey); sign != c.wantSign {
+			t.Errorf("%s: sign = %s, want %s", c.name, sign, c.wantSign)
+		}
+	}
+}
+
+func TestServiceProviderSign(t *testing.T) {
+	ctx := &context.Context{AppID: "wxsp", PayMchID: "1900000109", PayKey: testPayKey}
+	sp := NewPay(ctx).WithSubMerchant(SubMerchant{SubAppID: "wxsub", SubMchID: "1900000110"})
+
+	query := func(pcf *Pay) map[string]interface{} {
+		param := map[string]interface{}{
+			"appid":          pcf.AppID,
+			"mch_id":         pcf.PayMchID,
+			"nonce_str":      testNonceStr,
+			"transaction_id": "",
+			"out_trade_no":   "20150806125346",
+			"sign_type":      SignTypeMD5,
+		}
+		pcf.setSubMerchantParam(param)
+		return param
+	}
+	unified := map[string]interface{}{
+		"appid":            sp.AppID,
+		"body":             "test",
+		"mch_id":           sp.PayMchID,
+		"nonce_str":        testNonceStr,
+		"out_trade_no":     "20150806125346",
+		"spbill_create_ip": "127.0.0.1",
+		"total_fee":        Amount(101),
+		"trade_type":       "JSAPI",
+		"openid":           "",
+		"sub_openid":       "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o",
+		"sign_type":        SignTypeMD5,
+		"notify_url":       "https://example.com/notify",
+	}
+	sp.setSubMerchantParam(unified)
+
+	checkSign(t, []signCase{
+		{
+			name:     "service provider query",
+			param:    query(sp),
+			signType: SignTypeMD5,
+			want:     "appid=wxsp&mch_id=1900000109&nonce_str=" + testNonceStr + "&out_trade_no=20150806125346&sign_type=MD5&sub_appid=wxsub&sub_mch_id=1900000110",
+			wantSign: "4B00C054B8E3A6DE067F999024CE7531",
+		},
+		{
+			name:     "normal merchant query",
+			param:    query(NewPay(ctx)),
+			signType: SignTypeMD5,
+			want:     "appid=wxsp&mch_id=1900000109&nonce_str=" + testNonceStr + "&out_trade_no=20150806125346&sign_type=MD5",
+			wantSign: "C7BDE850D4594A22230FDA14D2A7B915",
+		},
+		{
+			name:     "service provider unified order",
+			param:    unified,
+			signType: SignTypeMD5,
+			want: "appid=wxsp&body=test&mch_id=1900000109&nonce_str=" + testNonceStr + "&notify_url=https://example.com/notify" +
+				"&out_trade_no=20150806125346&sign_type=MD5&spbill_create_ip=127.0.0.1&sub_appid=wxsub&sub_mch_id=1900000110" +
+				"&sub_openid=oUpF8uMuAJO_M2pxb1Q9zNjWeS6o&total_fee=101&trade_type=JSAPI",
+			wantSign: "50E81F1DC900555F1BB14D28F2D1B306",
+		},
+	})
+}
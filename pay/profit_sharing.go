@@ -0,0 +1,412 @@
+package pay
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+var (
+	profitSharingAddReceiverGateway    = "https://api.mch.weixin.qq.com/pay/profitsharingaddreceiver"
+	profitSharingRemoveReceiverGateway = "https://api.mch.weixin.qq.com/pay/profitsharingremovereceiver"
+	profitSharingGateway               = "https://api.mch.weixin.qq.com/secapi/pay/profitsharing"
+	multiProfitSharingGateway          = "https://api.mch.weixin.qq.com/secapi/pay/multiprofitsharing"
+	profitSharingQueryGateway          = "https://api.mch.weixin.qq.com/pay/profitsharingquery"
+	profitSharingReturnGateway         = "https://api.mch.weixin.qq.com/secapi/pay/profitsharingreturn"
+	profitSharingFinishGateway         = "https://api.mch.weixin.qq.com/secapi/pay/profitsharingfinish"
+	profitSharingAmountQueryGateway    = "https://api.mch.weixin.qq.com/pay/profitsharingorderamountquery"
+)
+
+const (
+	// ReceiverTypeMerchant 商户号
+	ReceiverTypeMerchant = "MERCHANT_ID"
+	// ReceiverTypeOpenID 个人openid
+	ReceiverTypeOpenID = "PERSONAL_OPENID"
+	// ReceiverTypeSubOpenID 个人sub_openid
+	ReceiverTypeSubOpenID = "PERSONAL_SUB_OPENID"
+)
+
+// ProfitSharingReceiver 分账接收方
+type ProfitSharingReceiver struct {
+	Type           string `json:"type"`                      // 分账接收方类型
+	Account        string `json:"account"`                   // 分账接收方帐号
+	Name           string `json:"name,omitempty"`            // 分账接收方全称，类型为商户号时必填
+	RelationType   string `json:"relation_type,omitempty"`   // 与分账方的关系类型
+	CustomRelation string `json:"custom_relation,omitempty"` // 自定义的分账关系
+}
+
+// ProfitSharingReceiverAmount 分账接收方及分账金额
+type ProfitSharingReceiverAmount struct {
+	Type        string `json:"type"`
+	Account     string `json:"account"`
+	Amount      Amount `json:"amount"` // 分账金额，单位为分
+	Description string `json:"description"`
+}
+
+// ProfitSharingReceiverResult 分账结果中的接收方
+type ProfitSharingReceiverResult struct {
+	ProfitSharingReceiverAmount
+	Result     string `json:"result"` // PENDING CLOSED SUCCESS
+	FinishTime string `json:"finish_time"`
+	FailReason string `json:"fail_reason"`
+	DetailID   string `json:"detail_id"`
+}
+
+// ProfitSharingParams 请求单次/多次分账参数
+type ProfitSharingParams struct {
+	TransactionID string
+	OutOrderNo    string // 商户分账单号
+	Receivers     []ProfitSharingReceiverAmount
+}
+
+// ProfitSharingReturnParams 分账回退参数，OrderID 和 OutOrderNo 二选一
+type ProfitSharingReturnParams struct {
+	OrderID           string
+	OutOrderNo        string
+	OutReturnNo       string // 商户回退单号
+	ReturnAccountType string // 回退方类型，暂只支持 MERCHANT_ID
+	ReturnAccount     string // 回退方账号
+	ReturnAmount      Amount
+	Description       string
+}
+
+// ProfitSharingFinishParams 完结分账参数
+type ProfitSharingFinishParams struct {
+	TransactionID string
+	OutOrderNo    string
+	Amount        Amount // 分账金额，完结分账时一般为 0
+	Description   string
+}
+
+// profitSharingRequest 分账接口请求参数，各接口按需填充
+type profitSharingRequest struct {
+	XMLName           xml.Name `xml:"xml"`
+	MchID             string   `xml:"mch_id"`
+	SubMchID          string   `xml:"sub_mch_id,omitempty"`
+	AppID             string   `xml:"appid,omitempty"`
+	SubAppID          string   `xml:"sub_appid,omitempty"`
+	NonceStr          string   `xml:"nonce_str"`
+	Sign              string   `xml:"sign"`
+	SignType          string   `xml:"sign_type"`
+	TransactionID     string   `xml:"transaction_id,omitempty"`
+	OutOrderNo        string   `xml:"out_order_no,omitempty"`
+	OrderID           string   `xml:"order_id,omitempty"`
+	OutReturnNo       string   `xml:"out_return_no,omitempty"`
+	ReturnAccountType string   `xml:"return_account_type,omitempty"`
+	ReturnAccount     string   `xml:"return_account,omitempty"`
+	ReturnAmount      string   `xml:"return_amount,omitempty"`
+	Amount            string   `xml:"amount,omitempty"`
+	Description       string   `xml:"description,omitempty"`
+	Receiver          string   `xml:"receiver,omitempty"`
+	Receivers         string   `xml:"receivers,omitempty"`
+}
+
+// ProfitSharingBase 分账接口公用返回
+type ProfitSharingBase struct {
+	ReturnCode string `xml:"return_code"`
+	ReturnMsg  string `xml:"return_msg"`
+	ResultCode string `xml:"result_code,omitempty"`
+	ErrCode    string `xml:"err_code,omitempty"`
+	ErrCodeDes string `xml:"err_code_des,omitempty"`
+	MchID      string `xml:"mch_id,omitempty"`
+	SubMchID   string `xml:"sub_mch_id,omitempty"`
+	AppID      string `xml:"appid,omitempty"`
+	SubAppID   string `xml:"sub_appid,omitempty"`
+	NonceStr   string `xml:"nonce_str,omitempty"`
+	Sign       string `xml:"sign,omitempty"`
+}
+
+// ProfitSharingReceiverResponse 添加/删除分账接收方返回
+type ProfitSharingReceiverResponse struct {
+	ProfitSharingBase
+	Receiver string `xml:"receiver,omitempty"` // 分账接收方 json
+}
+
+// ProfitSharingResponse 请求分账返回
+type ProfitSharingResponse struct {
+	ProfitSharingBase
+	TransactionID string `xml:"transaction_id,omitempty"`
+	OutOrderNo    string `xml:"out_order_no,omitempty"`
+	OrderID       string `xml:"order_id,omitempty"` // 微信分账单号
+	Status        string `xml:"status,omitempty"`   // PROCESSING FINISHED
+}
+
+// ProfitSharingQueryResponse 查询分账结果返回
+type ProfitSharingQueryResponse struct {
+	ProfitSharingBase
+	TransactionID string `xml:"transaction_id,omitempty"`
+	OutOrderNo    string `xml:"out_order_no,omitempty"`
+	OrderID       string `xml:"order_id,omitempty"`
+	Status        string `xml:"status,omitempty"` // ACCEPTED PROCESSING FINISHED CLOSED
+	CloseReason   string `xml:"close_reason,omitempty"`
+	RawReceivers  string `xml:"receivers,omitempty"`
+	Amount        Amount `xml:"amount,omitempty"`
+	Description   string `xml:"description,omitempty"`
+
+	Receivers []ProfitSharingReceiverResult `xml:"-"`
+}
+
+// ProfitSharingReturnResponse 分账回退返回
+type ProfitSharingReturnResponse struct {
+	ProfitSharingBase
+	OrderID           string `xml:"order_id,omitempty"`
+	OutOrderNo        string `xml:"out_order_no,omitempty"`
+	OutReturnNo       string `xml:"out_return_no,omitempty"`
+	ReturnNo          string `xml:"return_no,omitempty"` // 微信回退单号
+	ReturnAccountType string `xml:"return_account_type,omitempty"`
+	ReturnAccount     string `xml:"return_account,omitempty"`
+	ReturnAmount      Amount `xml:"return_amount,omitempty"`
+	Description       string `xml:"description,omitempty"`
+	Result            string `xml:"result,omitempty"` // PROCESSING SUCCESS FAILED
+	FailReason        string `xml:"fail_reason,omitempty"`
+	FinishTime        string `xml:"finish_time,omitempty"`
+}
+
+// ProfitSharingFinishResponse 完结分账返回
+type ProfitSharingFinishResponse struct {
+	ProfitSharingBase
+	TransactionID string `xml:"transaction_id,omitempty"`
+	OutOrderNo    string `xml:"out_order_no,omitempty"`
+	OrderID       string `xml:"order_id,omitempty"`
+}
+
+// ProfitSharingAmountResponse 查询订单待分账金额返回
+type ProfitSharingAmountResponse struct {
+	ProfitSharingBase
+	TransactionID string `xml:"transaction_id,omitempty"`
+	UnsplitAmount Amount `xml:"unsplit_amount,omitempty"` // 订单剩余待分金额，单位为分
+}
+
+func (b ProfitSharingBase) checkResult(apiName string, rawRet []byte) error {
+	if b.ReturnCode != "SUCCESS" {
+		return fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	}
+	if b.ResultCode != "SUCCESS" {
+		return fmt.Errorf("%s error, errcode=%s,errmsg=%s", apiName, b.ErrCode, b.ErrCodeDes)
+	}
+	return nil
+}
+
+// AddProfitSharingReceiver 添加分账接收方
+func (pcf *Pay) AddProfitSharingReceiver(receiver ProfitSharingReceiver) (rsp ProfitSharingReceiverResponse, err error) {
+	rawRet, err := pcf.postReceiver(profitSharingAddReceiverGateway, receiver)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	err = rsp.checkResult("add profit sharing receiver", rawRet)
+	return
+}
+
+// RemoveProfitSharingReceiver 删除分账接收方
+func (pcf *Pay) RemoveProfitSharingReceiver(receiver ProfitSharingReceiver) (rsp ProfitSharingReceiverResponse, err error) {
+	// 删除时只需要类型和账号
+	receiver = ProfitSharingReceiver{Type: receiver.Type, Account: receiver.Account}
+	rawRet, err := pcf.postReceiver(profitSharingRemoveReceiverGateway, receiver)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	err = rsp.checkResult("remove profit sharing receiver", rawRet)
+	return
+}
+
+func (pcf *Pay) postReceiver(gateway string, receiver ProfitSharingReceiver) ([]byte, error) {
+	data, err := json.Marshal(receiver)
+	if err != nil {
+		return nil, err
+	}
+	subAppID, subMchID := pcf.subMerchantInfo()
+	request := &profitSharingRequest{
+		AppID:    pcf.AppID,
+		SubAppID: subAppID,
+		SubMchID: subMchID,
+		Receiver: string(data),
+	}
+	return pcf.postProfitSharing(gateway, request, false)
+}
+
+// ProfitSharing 请求单次分账，分账完成后订单剩余金额自动解冻给商户
+func (pcf *Pay) ProfitSharing(p *ProfitSharingParams) (rsp ProfitSharingResponse, err error) {
+	return pcf.profitSharing(profitSharingGateway, p)
+}
+
+// MultiProfitSharing 请求多次分账，需调用 FinishProfitSharing 解冻剩余金额
+func (pcf *Pay) MultiProfitSharing(p *ProfitSharingParams) (rsp ProfitSharingResponse, err error) {
+	return pcf.profitSharing(multiProfitSharingGateway, p)
+}
+
+func (pcf *Pay) profitSharing(gateway string, p *ProfitSharingParams) (rsp ProfitSharingResponse, err error) {
+	if len(p.Receivers) == 0 {
+		err = fmt.Errorf("profit sharing receivers is empty")
+		return
+	}
+	for _, receiver := range p.Receivers {
+		if err = receiver.Amount.Validate("receiver amount"); err != nil {
+			return
+		}
+	}
+	data, err := json.Marshal(p.Receivers)
+	if err != nil {
+		return
+	}
+	subAppID, subMchID := pcf.subMerchantInfo()
+	request := &profitSharingRequest{
+		AppID:         pcf.AppID,
+		SubAppID:      subAppID,
+		SubMchID:      subMchID,
+		TransactionID: p.TransactionID,
+		OutOrderNo:    p.OutOrderNo,
+		Receivers:     string(data),
+	}
+	rawRet, err := pcf.postProfitSharing(gateway, request, true)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	err = rsp.checkResult("profit sharing", rawRet)
+	return
+}
+
+// QueryProfitSharing 查询分账结果
+func (pcf *Pay) QueryProfitSharing(transactionID, outOrderNo string) (rsp ProfitSharingQueryResponse, err error) {
+	_, subMchID := pcf.subMerchantInfo()
+	request := &profitSharingRequest{
+		SubMchID:      subMchID,
+		TransactionID: transactionID,
+		OutOrderNo:    outOrderNo,
+	}
+	rawRet, err := pcf.postProfitSharing(profitSharingQueryGateway, request, false)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	if err = rsp.checkResult("query profit sharing", rawRet); err != nil {
+		return
+	}
+	if rsp.RawReceivers != "" {
+		err = json.Unmarshal([]byte(rsp.RawReceivers), &rsp.Receivers)
+	}
+	return
+}
+
+// ReturnProfitSharing 分账回退
+func (pcf *Pay) ReturnProfitSharing(p *ProfitSharingReturnParams) (rsp ProfitSharingReturnResponse, err error) {
+	if p.OrderID == "" && p.OutOrderNo == "" {
+		err = fmt.Errorf("order_id and out_order_no cannot both be empty")
+		return
+	}
+	if err = p.ReturnAmount.Validate("return_amount"); err != nil {
+		return
+	}
+	if p.ReturnAccountType == "" {
+		p.ReturnAccountType = ReceiverTypeMerchant
+	}
+	_, subMchID := pcf.subMerchantInfo()
+	request := &profitSharingRequest{
+		AppID:             pcf.AppID,
+		SubMchID:          subMchID,
+		OrderID:           p.OrderID,
+		OutOrderNo:        p.OutOrderNo,
+		OutReturnNo:       p.OutReturnNo,
+		ReturnAccountType: p.ReturnAccountType,
+		ReturnAccount:     p.ReturnAccount,
+		ReturnAmount:      p.ReturnAmount.String(),
+		Description:       p.Description,
+	}
+	rawRet, err := pcf.postProfitSharing(profitSharingReturnGateway, request, true)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	err = rsp.checkResult("return profit sharing", rawRet)
+	return
+}
+
+// FinishProfitSharing 完结分账，解冻订单剩余待分金额给商户
+func (pcf *Pay) FinishProfitSharing(p *ProfitSharingFinishParams) (rsp ProfitSharingFinishResponse, err error) {
+	_, subMchID := pcf.subMerchantInfo()
+	request := &profitSharingRequest{
+		AppID:         pcf.AppID,
+		SubMchID:      subMchID,
+		TransactionID: p.TransactionID,
+		OutOrderNo:    p.OutOrderNo,
+		Amount:        p.Amount.String(),
+		Description:   p.Description,
+	}
+	rawRet, err := pcf.postProfitSharing(profitSharingFinishGateway, request, true)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	err = rsp.checkResult("finish profit sharing", rawRet)
+	return
+}
+
+// QueryProfitSharingAmount 查询订单待分账金额
+func (pcf *Pay) QueryProfitSharingAmount(transactionID string) (rsp ProfitSharingAmountResponse, err error) {
+	request := &profitSharingRequest{
+		TransactionID: transactionID,
+	}
+	rawRet, err := pcf.postProfitSharing(profitSharingAmountQueryGateway, request, false)
+	if err != nil {
+		return
+	}
+	if err = xml.Unmarshal(rawRet, &rsp); err != nil {
+		return
+	}
+	err = rsp.checkResult("query profit sharing amount", rawRet)
+	return
+}
+
+// postProfitSharing 签名并发送分账请求，分账接口只支持 HMAC-SHA256 签名
+func (pcf *Pay) postProfitSharing(gateway string, request *profitSharingRequest, withTLS bool) ([]byte, error) {
+	request.MchID = pcf.PayMchID
+	request.NonceStr = util.RandomStr(32)
+	request.SignType = SignTypeHMACSHA256
+
+	param := map[string]interface{}{
+		"mch_id":              request.MchID,
+		"sub_mch_id":          request.SubMchID,
+		"appid":               request.AppID,
+		"sub_appid":           request.SubAppID,
+		"nonce_str":           request.NonceStr,
+		"sign_type":           request.SignType,
+		"transaction_id":      request.TransactionID,
+		"out_order_no":        request.OutOrderNo,
+		"order_id":            request.OrderID,
+		"out_return_no":       request.OutReturnNo,
+		"return_account_type": request.ReturnAccountType,
+		"return_account":      request.ReturnAccount,
+		"return_amount":       request.ReturnAmount,
+		"amount":              request.Amount,
+		"description":         request.Description,
+		"receiver":            request.Receiver,
+		"receivers":           request.Receivers,
+	}
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return nil, err
+	}
+	str := orderParam(param, "&key="+payKey)
+	request.Sign = makeSign(str, SignTypeHMACSHA256, payKey)
+
+	if withTLS {
+		return pcf.postXMLWithCert(gateway, request)
+	}
+	return util.PostXML(pcf.gateway(gateway), request)
+}
@@ -0,0 +1,27 @@
+package pay
+
+import "testing"
+
+func TestProfitSharingSign(t *testing.T) {
+	checkSign(t, []signCase{
+		{
+			name: "profit sharing",
+			param: map[string]interface{}{
+				"mch_id":         "1900000109",
+				"sub_mch_id":     "1900000110",
+				"appid":          "wxsp",
+				"sub_appid":      "",
+				"nonce_str":      testNonceStr,
+				"sign_type":      SignTypeHMACSHA256,
+				"transaction_id": "4208450740201411110007820472",
+				"out_order_no":   "P20150806125346",
+				"receivers":      `[{"type":"MERCHANT_ID","account":"190001001","amount":100,"description":"分到商户"}]`,
+			},
+			signType: SignTypeHMACSHA256,
+			want: "appid=wxsp&mch_id=1900000109&nonce_str=" + testNonceStr + "&out_order_no=P20150806125346" +
+				`&receivers=[{"type":"MERCHANT_ID","account":"190001001","amount":100,"description":"分到商户"}]` +
+				"&sign_type=HMAC-SHA256&sub_mch_id=1900000110&transaction_id=4208450740201411110007820472",
+			wantSign: "8BB601D9930C487F92FC18955036DB4ED7AE47267A877F07478D3BE3DF30FE91",
+		},
+	})
+}
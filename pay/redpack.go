@@ -0,0 +1,239 @@
+package pay
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+var (
+	sendRedPackGateway      = "https://api.mch.weixin.qq.com/mmpaymkttransfers/sendredpack"
+	sendGroupRedPackGateway = "https://api.mch.weixin.qq.com/mmpaymkttransfers/sendgroupredpack"
+	queryRedPackGateway     = "https://api.mch.weixin.qq.com/mmpaymkttransfers/gethbinfo"
+)
+
+// RedPackParams 现金红包参数
+type RedPackParams struct {
+	MchBillNo   string // 商户订单号
+	SendName    string // 商户名称
+	ReOpenID    string // 接收红包的用户openid
+	TotalAmount Amount // 付款金额，单位为分
+	TotalNum    int    // 红包发放总人数，普通红包为 1，裂变红包不小于 3
+	Wishing     string // 红包祝福语
+	ClientIP    string // 调用接口的机器Ip地址，裂变红包不需要
+	ActName     string // 活动名称
+	Remark      string // 备注
+	SceneID     string // 场景id，金额大于200元或者小于1元时必填
+	RiskInfo    string // 活动信息
+}
+
+// redPackRequest 现金红包请求参数
+type redPackRequest struct {
+	XMLName     xml.Name `xml:"xml"`
+	NonceStr    string   `xml:"nonce_str"`
+	Sign        string   `xml:"sign"`
+	MchBillNo   string   `xml:"mch_billno"`
+	MchID       string   `xml:"mch_id"`
+	WxAppID     string   `xml:"wxappid"`
+	SendName    string   `xml:"send_name"`
+	ReOpenID    string   `xml:"re_openid"`
+	TotalAmount Amount   `xml:"total_amount"`
+	TotalNum    int      `xml:"total_num"`
+	AmtType     string   `xml:"amt_type,omitempty"`
+	Wishing     string   `xml:"wishing"`
+	ClientIP    string   `xml:"client_ip,omitempty"`
+	ActName     string   `xml:"act_name"`
+	Remark      string   `xml:"remark"`
+	SceneID     string   `xml:"scene_id,omitempty"`
+	RiskInfo    string   `xml:"risk_info,omitempty"`
+}
+
+// RedPackResponse 发放红包返回
+type RedPackResponse struct {
+	ReturnCode  string `xml:"return_code"`
+	ReturnMsg   string `xml:"return_msg"`
+	ResultCode  string `xml:"result_code,omitempty"`
+	ErrCode     string `xml:"err_code,omitempty"`
+	ErrCodeDes  string `xml:"err_code_des,omitempty"`
+	MchBillNo   string `xml:"mch_billno,omitempty"`
+	MchID       string `xml:"mch_id,omitempty"`
+	WxAppID     string `xml:"wxappid,omitempty"`
+	ReOpenID    string `xml:"re_openid,omitempty"`
+	TotalAmount Amount `xml:"total_amount,omitempty"`
+	SendListID  string `xml:"send_listid,omitempty"` // 微信红包订单号
+}
+
+// queryRedPackRequest 查询红包记录请求参数
+type queryRedPackRequest struct {
+	XMLName   xml.Name `xml:"xml"`
+	NonceStr  string   `xml:"nonce_str"`
+	Sign      string   `xml:"sign"`
+	MchBillNo string   `xml:"mch_billno"`
+	MchID     string   `xml:"mch_id"`
+	AppID     string   `xml:"appid"`
+	BillType  string   `xml:"bill_type"`
+}
+
+// RedPackReceiver 红包领取记录
+type RedPackReceiver struct {
+	OpenID  string `xml:"openid"`
+	Amount  Amount `xml:"amount"`
+	RcvTime string `xml:"rcv_time"`
+}
+
+// QueryRedPackResponse 查询红包记录返回
+type QueryRedPackResponse struct {
+	ReturnCode   string            `xml:"return_code"`
+	ReturnMsg    string            `xml:"return_msg"`
+	ResultCode   string            `xml:"result_code,omitempty"`
+	ErrCode      string            `xml:"err_code,omitempty"`
+	ErrCodeDes   string            `xml:"err_code_des,omitempty"`
+	MchBillNo    string            `xml:"mch_billno,omitempty"`
+	MchID        string            `xml:"mch_id,omitempty"`
+	DetailID     string            `xml:"detail_id,omitempty"`
+	Status       string            `xml:"status,omitempty"`    // SENDING SENT FAILED RECEIVED RFUND_ING REFUND
+	SendType     string            `xml:"send_type,omitempty"` // API UPLOAD ACTIVITY
+	HbType       string            `xml:"hb_type,omitempty"`   // GROUP NORMAL
+	TotalNum     int               `xml:"total_num,omitempty"`
+	TotalAmount  Amount            `xml:"total_amount,omitempty"`
+	Reason       string            `xml:"reason,omitempty"`
+	SendTime     string            `xml:"send_time,omitempty"`
+	RefundTime   string            `xml:"refund_time,omitempty"`
+	RefundAmount Amount            `xml:"refund_amount,omitempty"`
+	Wishing      string            `xml:"wishing,omitempty"`
+	Remark       string            `xml:"remark,omitempty"`
+	ActName      string            `xml:"act_name,omitempty"`
+	HbList       []RedPackReceiver `xml:"hblist>hbinfo"`
+}
+
+// SendRedPack 发放普通红包
+func (pcf *Pay) SendRedPack(p *RedPackParams) (rsp RedPackResponse, err error) {
+	if p.TotalNum == 0 {
+		p.TotalNum = 1
+	}
+	return pcf.sendRedPack(sendRedPackGateway, p, "")
+}
+
+// SendGroupRedPack 发放裂变红包
+func (pcf *Pay) SendGroupRedPack(p *RedPackParams) (rsp RedPackResponse, err error) {
+	if p.TotalNum < 3 {
+		err = fmt.Errorf("total_num of group red pack must be at least 3")
+		return
+	}
+	// 裂变红包不需要 client_ip
+	p.ClientIP = ""
+	return pcf.sendRedPack(sendGroupRedPackGateway, p, "ALL_RAND")
+}
+
+func (pcf *Pay) sendRedPack(gateway string, p *RedPackParams, amtType string) (rsp RedPackResponse, err error) {
+	if err = p.TotalAmount.Validate("total_amount"); err != nil {
+		return
+	}
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["nonce_str"] = nonceStr
+	param["mch_billno"] = p.MchBillNo
+	param["mch_id"] = pcf.PayMchID
+	param["wxappid"] = pcf.AppID
+	param["send_name"] = p.SendName
+	param["re_openid"] = p.ReOpenID
+	param["total_amount"] = p.TotalAmount
+	param["total_num"] = p.TotalNum
+	param["amt_type"] = amtType
+	param["wishing"] = p.Wishing
+	param["client_ip"] = p.ClientIP
+	param["act_name"] = p.ActName
+	param["remark"] = p.Remark
+	param["scene_id"] = p.SceneID
+	param["risk_info"] = p.RiskInfo
+
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
+	str := orderParam(param, bizKey)
+	sign := util.MD5Sum(str)
+	request := redPackRequest{
+		NonceStr:    nonceStr,
+		Sign:        sign,
+		MchBillNo:   p.MchBillNo,
+		MchID:       pcf.PayMchID,
+		WxAppID:     pcf.AppID,
+		SendName:    p.SendName,
+		ReOpenID:    p.ReOpenID,
+		TotalAmount: p.TotalAmount,
+		TotalNum:    p.TotalNum,
+		AmtType:     amtType,
+		Wishing:     p.Wishing,
+		ClientIP:    p.ClientIP,
+		ActName:     p.ActName,
+		Remark:      p.Remark,
+		SceneID:     p.SceneID,
+		RiskInfo:    p.RiskInfo,
+	}
+	rawRet, err := pcf.postXMLWithCert(gateway, request)
+	if err != nil {
+		return
+	}
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode == "SUCCESS" {
+		if rsp.ResultCode == "SUCCESS" {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("send red pack error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		return
+	}
+	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	return
+}
+
+// QueryRedPack 查询红包记录
+func (pcf *Pay) QueryRedPack(mchBillNo string) (rsp QueryRedPackResponse, err error) {
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["nonce_str"] = nonceStr
+	param["mch_billno"] = mchBillNo
+	param["mch_id"] = pcf.PayMchID
+	param["appid"] = pcf.AppID
+	param["bill_type"] = "MCHT"
+
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
+	str := orderParam(param, bizKey)
+	sign := util.MD5Sum(str)
+	request := queryRedPackRequest{
+		NonceStr:  nonceStr,
+		Sign:      sign,
+		MchBillNo: mchBillNo,
+		MchID:     pcf.PayMchID,
+		AppID:     pcf.AppID,
+		BillType:  "MCHT",
+	}
+	rawRet, err := pcf.postXMLWithCert(queryRedPackGateway, request)
+	if err != nil {
+		return
+	}
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode == "SUCCESS" {
+		if rsp.ResultCode == "SUCCESS" {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("query red pack error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		return
+	}
+	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	return
+}
@@ -0,0 +1,42 @@
+package pay
+
+import (
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+)
+
+func TestRedPackSign(t *testing.T) {
+	checkSign(t, []signCase{
+		{
+			name: "red pack",
+			param: map[string]interface{}{
+				"nonce_str":    testNonceStr,
+				"mch_billno":   "R20150806125346",
+				"mch_id":       "1900000109",
+				"wxappid":      "wxsp",
+				"send_name":    "商户",
+				"re_openid":    "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o",
+				"total_amount": Amount(100),
+				"total_num":    1,
+				"amt_type":     "",
+				"wishing":      "恭喜发财",
+				"client_ip":    "127.0.0.1",
+				"act_name":     "活动",
+				"remark":       "备注",
+				"scene_id":     "",
+				"risk_info":    "",
+			},
+			signType: SignTypeMD5,
+			want: "act_name=活动&client_ip=127.0.0.1&mch_billno=R20150806125346&mch_id=1900000109&nonce_str=" + testNonceStr +
+				"&re_openid=oUpF8uMuAJO_M2pxb1Q9zNjWeS6o&remark=备注&send_name=商户&total_amount=100&total_num=1&wishing=恭喜发财&wxappid=wxsp",
+			wantSign: "C3B0CB682FB2AB08534B6A8ADA26FEC5",
+		},
+	})
+}
+
+func TestSendGroupRedPackParamCheck(t *testing.T) {
+	if _, err := NewPay(&context.Context{}).SendGroupRedPack(&RedPackParams{TotalAmount: 300, TotalNum: 2}); err == nil {
+		t.Error("group red pack with less than 3 receivers should be rejected")
+	}
+}
@@ -3,6 +3,7 @@ package pay
 import (
 	"encoding/xml"
 	"fmt"
+	"net/http"
 
 	"github.com/pengshang1995/wechat-sdk/util"
 )
@@ -13,8 +14,8 @@ var refundGateway = "https://api.mch.weixin.qq.com/secapi/pay/refund"
 type RefundParams struct {
 	TransactionID string
 	OutRefundNo   string
-	TotalFee      string
-	RefundFee     string
+	TotalFee      Amount
+	RefundFee     Amount
 	RefundDesc    string
 	// RootCa        string //ca证书
 	P12 []byte // 微信加密证书
@@ -24,13 +25,15 @@ type RefundParams struct {
 type refundRequest struct {
 	AppID         string `xml:"appid"`
 	MchID         string `xml:"mch_id"`
+	SubAppID      string `xml:"sub_appid,omitempty"`
+	SubMchID      string `xml:"sub_mch_id,omitempty"`
 	NonceStr      string `xml:"nonce_str"`
 	Sign          string `xml:"sign"`
 	SignType      string `xml:"sign_type,omitempty"`
 	TransactionID string `xml:"transaction_id"`
 	OutRefundNo   string `xml:"out_refund_no"`
-	TotalFee      string `xml:"total_fee"`
-	RefundFee     string `xml:"refund_fee"`
+	TotalFee      Amount `xml:"total_fee"`
+	RefundFee     Amount `xml:"refund_fee"`
 	RefundDesc    string `xml:"refund_desc,omitempty"`
 	// NotifyUrl     string `xml:"notify_url,omitempty"`
 }
@@ -41,6 +44,8 @@ type RefundResponse struct {
 	ReturnMsg           string `xml:"return_msg"`
 	AppID               string `xml:"appid,omitempty"`
 	MchID               string `xml:"mch_id,omitempty"`
+	SubAppID            string `xml:"sub_appid,omitempty"`
+	SubMchID            string `xml:"sub_mch_id,omitempty"`
 	NonceStr            string `xml:"nonce_str,omitempty"`
 	Sign                string `xml:"sign,omitempty"`
 	ResultCode          string `xml:"result_code,omitempty"`
@@ -50,24 +55,28 @@ type RefundResponse struct {
 	OutTradeNo          string `xml:"out_trade_no,omitempty"`
 	OutRefundNo         string `xml:"out_refund_no,omitempty"`
 	RefundID            string `xml:"refund_id,omitempty"`
-	RefundFee           string `xml:"refund_fee,omitempty"`
-	SettlementRefundFee string `xml:"settlement_refund_fee,omitempty"`
-	TotalFee            string `xml:"total_fee,omitempty"`
-	SettlementTotalFee  string `xml:"settlement_total_fee,omitempty"`
+	RefundFee           Amount `xml:"refund_fee,omitempty"`
+	SettlementRefundFee Amount `xml:"settlement_refund_fee,omitempty"`
+	TotalFee            Amount `xml:"total_fee,omitempty"`
+	SettlementTotalFee  Amount `xml:"settlement_total_fee,omitempty"`
 	FeeType             string `xml:"fee_type,omitempty"`
-	CashFee             string `xml:"cash_fee,omitempty"`
+	CashFee             Amount `xml:"cash_fee,omitempty"`
 	CashFeeType         string `xml:"cash_fee_type,omitempty"`
 }
 
 // Refund 退款申请
 func (pcf *Pay) Refund(p *RefundParams) (rsp RefundResponse, err error) {
-	nonceStr := util.RandomStr(32)
-	var p12 []byte
-	if p.P12 == nil {
-		p12 = pcf.P12
-	} else {
-		p12 = p.P12
+	if err = p.TotalFee.Validate("total_fee"); err != nil {
+		return
+	}
+	if err = p.RefundFee.Validate("refund_fee"); err != nil {
+		return
+	}
+	if p.RefundFee > p.TotalFee {
+		err = fmt.Errorf("refund_fee %s cannot be greater than total_fee %s", p.RefundFee.Yuan(), p.TotalFee.Yuan())
+		return
 	}
+	nonceStr := util.RandomStr(32)
 	param := make(map[string]interface{})
 	param["appid"] = pcf.AppID
 	param["mch_id"] = pcf.PayMchID
@@ -78,13 +87,21 @@ func (pcf *Pay) Refund(p *RefundParams) (rsp RefundResponse, err error) {
 	param["total_fee"] = p.TotalFee
 	param["sign_type"] = "MD5"
 	param["transaction_id"] = p.TransactionID
+	pcf.setSubMerchantParam(param)
 
-	bizKey := "&key=" + pcf.PayKey
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
 	str := orderParam(param, bizKey)
 	sign := util.MD5Sum(str)
+	subAppID, subMchID := pcf.subMerchantInfo()
 	request := refundRequest{
 		AppID:         pcf.AppID,
 		MchID:         pcf.PayMchID,
+		SubAppID:      subAppID,
+		SubMchID:      subMchID,
 		NonceStr:      nonceStr,
 		Sign:          sign,
 		SignType:      "MD5",
@@ -94,7 +111,17 @@ func (pcf *Pay) Refund(p *RefundParams) (rsp RefundResponse, err error) {
 		RefundFee:     p.RefundFee,
 		RefundDesc:    p.RefundDesc,
 	}
-	rawRet, err := util.PostXMLWithTLS(refundGateway, request, p12, pcf.PayMchID)
+	var rawRet []byte
+	if p.P12 != nil {
+		// 单次调用指定的证书，按证书指纹复用 http 客户端
+		var client *http.Client
+		if client, err = pcf.GetP12TLSClient(p.P12); err != nil {
+			return
+		}
+		rawRet, err = util.PostXMLWithClient(client, pcf.gateway(refundGateway), request)
+	} else {
+		rawRet, err = pcf.postXMLWithCert(refundGateway, request)
+	}
 	if err != nil {
 		return
 	}
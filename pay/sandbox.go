@@ -0,0 +1,102 @@
+package pay
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+var sandboxSignKeyGateway = "https://api.mch.weixin.qq.com/sandboxnew/pay/getsignkey"
+
+const (
+	mchAPIHost     = "https://api.mch.weixin.qq.com/"
+	sandboxAPIHost = "https://api.mch.weixin.qq.com/sandboxnew/"
+)
+
+// sandboxSignKeyRequest 获取沙箱密钥请求参数
+type sandboxSignKeyRequest struct {
+	XMLName  xml.Name `xml:"xml"`
+	MchID    string   `xml:"mch_id"`
+	NonceStr string   `xml:"nonce_str"`
+	Sign     string   `xml:"sign"`
+}
+
+// sandboxSignKeyResponse 获取沙箱密钥返回
+type sandboxSignKeyResponse struct {
+	ReturnCode     string `xml:"return_code"`
+	ReturnMsg      string `xml:"return_msg"`
+	MchID          string `xml:"mch_id"`
+	SandboxSignKey string `xml:"sandbox_signkey"`
+}
+
+// WithSandbox 返回开启或关闭仿真测试模式的实例，不影响原实例及共用同一配置的其它实例
+// 开启后所有支付接口都请求 sandboxnew 网关，并使用 getsignkey 获取的沙箱密钥签名
+func (pcf *Pay) WithSandbox(enable bool) *Pay {
+	pay := *pcf
+	pay.sandbox = enable
+	return &pay
+}
+
+// IsSandbox 是否为仿真测试模式
+func (pcf *Pay) IsSandbox() bool {
+	return pcf.sandbox
+}
+
+// GetSandboxSignKey 获取沙箱密钥，获取后会缓存在 Cache 中
+func (pcf *Pay) GetSandboxSignKey() (signKey string, err error) {
+	cacheKey := pcf.CacheKeys().PaySandboxSignKey(pcf.PayMchID)
+	if val, ok := pcf.Cache.Get(cacheKey).(string); ok && val != "" {
+		signKey = val
+		return
+	}
+
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["mch_id"] = pcf.PayMchID
+	param["nonce_str"] = nonceStr
+	// 获取沙箱密钥时使用正式的商户密钥签名
+	str := orderParam(param, "&key="+pcf.PayKey)
+	request := sandboxSignKeyRequest{
+		MchID:    pcf.PayMchID,
+		NonceStr: nonceStr,
+		Sign:     util.MD5Sum(str),
+	}
+	rawRet, err := util.PostXML(sandboxSignKeyGateway, request)
+	if err != nil {
+		return
+	}
+	var rsp sandboxSignKeyResponse
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode != "SUCCESS" || rsp.SandboxSignKey == "" {
+		err = fmt.Errorf("get sandbox signkey error, return_msg=%s", rsp.ReturnMsg)
+		return
+	}
+	signKey = rsp.SandboxSignKey
+	err = pcf.Cache.Set(cacheKey, signKey, 24*time.Hour)
+	return
+}
+
+// signKey 返回当前模式下用于签名的密钥
+func (pcf *Pay) signKey() (string, error) {
+	if !pcf.sandbox {
+		return pcf.PayKey, nil
+	}
+	return pcf.GetSandboxSignKey()
+}
+
+// gateway 仿真测试模式下将接口地址改写到 sandboxnew 网关
+// 例如 https://api.mch.weixin.qq.com/secapi/pay/refund => https://api.mch.weixin.qq.com/sandboxnew/pay/refund
+func (pcf *Pay) gateway(uri string) string {
+	if !pcf.sandbox || !strings.HasPrefix(uri, mchAPIHost) || strings.HasPrefix(uri, sandboxAPIHost) {
+		return uri
+	}
+	path := strings.TrimPrefix(uri, mchAPIHost)
+	path = strings.TrimPrefix(path, "secapi/")
+	return sandboxAPIHost + path
+}
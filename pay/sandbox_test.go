@@ -0,0 +1,26 @@
+package pay
+
+import (
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+)
+
+func TestWithSandbox(t *testing.T) {
+	pcf := NewPay(&context.Context{})
+	sandbox := pcf.WithSandbox(true)
+	if pcf.IsSandbox() || !sandbox.IsSandbox() {
+		t.Fatal("WithSandbox should not change the original instance")
+	}
+	if !sandbox.WithSubMerchant(SubMerchant{SubMchID: "1"}).IsSandbox() {
+		t.Fatal("sub merchant instance should keep sandbox mode")
+	}
+
+	uri := "https://api.mch.weixin.qq.com/secapi/pay/refund"
+	if got := pcf.gateway(uri); got != uri {
+		t.Errorf("gateway = %s, want %s", got, uri)
+	}
+	if got := sandbox.gateway(uri); got != "https://api.mch.weixin.qq.com/sandboxnew/pay/refund" {
+		t.Errorf("sandbox gateway = %s", got)
+	}
+}
@@ -0,0 +1,206 @@
+package pay
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/pengshang1995/wechat-sdk/util"
+)
+
+var (
+	transferGateway      = "https://api.mch.weixin.qq.com/mmpaymkttransfers/promotion/transfers"
+	queryTransferGateway = "https://api.mch.weixin.qq.com/mmpaymkttransfers/gettransferinfo"
+)
+
+const (
+	// CheckNameNo 不校验真实姓名
+	CheckNameNo = "NO_CHECK"
+	// CheckNameForce 强校验真实姓名
+	CheckNameForce = "FORCE_CHECK"
+)
+
+// TransferParams 企业付款到零钱参数
+type TransferParams struct {
+	PartnerTradeNo string // 商户订单号
+	OpenID         string // 商户appid下用户的openid
+	CheckName      string // 校验用户姓名选项，默认 NO_CHECK
+	ReUserName     string // 收款用户姓名，CheckName 为 FORCE_CHECK 时必填
+	Amount         Amount // 付款金额，单位为分
+	Desc           string // 付款备注
+	CreateIP       string // 调用接口的机器Ip地址
+	DeviceInfo     string
+}
+
+// transferRequest 企业付款接口请求参数
+type transferRequest struct {
+	XMLName        xml.Name `xml:"xml"`
+	MchAppID       string   `xml:"mch_appid"`
+	MchID          string   `xml:"mchid"`
+	DeviceInfo     string   `xml:"device_info,omitempty"`
+	NonceStr       string   `xml:"nonce_str"`
+	Sign           string   `xml:"sign"`
+	PartnerTradeNo string   `xml:"partner_trade_no"`
+	OpenID         string   `xml:"openid"`
+	CheckName      string   `xml:"check_name"`
+	ReUserName     string   `xml:"re_user_name,omitempty"`
+	Amount         Amount   `xml:"amount"`
+	Desc           string   `xml:"desc"`
+	SpbillCreateIP string   `xml:"spbill_create_ip,omitempty"`
+}
+
+// TransferResponse 企业付款接口返回
+type TransferResponse struct {
+	ReturnCode     string `xml:"return_code"`
+	ReturnMsg      string `xml:"return_msg"`
+	MchAppID       string `xml:"mch_appid,omitempty"`
+	MchID          string `xml:"mchid,omitempty"`
+	DeviceInfo     string `xml:"device_info,omitempty"`
+	NonceStr       string `xml:"nonce_str,omitempty"`
+	ResultCode     string `xml:"result_code,omitempty"`
+	ErrCode        string `xml:"err_code,omitempty"`
+	ErrCodeDes     string `xml:"err_code_des,omitempty"`
+	PartnerTradeNo string `xml:"partner_trade_no,omitempty"`
+	PaymentNo      string `xml:"payment_no,omitempty"`   // 微信付款单号
+	PaymentTime    string `xml:"payment_time,omitempty"` // 付款成功时间
+}
+
+// queryTransferRequest 查询企业付款请求参数
+type queryTransferRequest struct {
+	XMLName        xml.Name `xml:"xml"`
+	NonceStr       string   `xml:"nonce_str"`
+	Sign           string   `xml:"sign"`
+	PartnerTradeNo string   `xml:"partner_trade_no"`
+	MchID          string   `xml:"mch_id"`
+	AppID          string   `xml:"appid"`
+}
+
+// QueryTransferResponse 查询企业付款返回
+type QueryTransferResponse struct {
+	ReturnCode     string `xml:"return_code"`
+	ReturnMsg      string `xml:"return_msg"`
+	ResultCode     string `xml:"result_code,omitempty"`
+	ErrCode        string `xml:"err_code,omitempty"`
+	ErrCodeDes     string `xml:"err_code_des,omitempty"`
+	PartnerTradeNo string `xml:"partner_trade_no,omitempty"`
+	AppID          string `xml:"appid,omitempty"`
+	MchID          string `xml:"mch_id,omitempty"`
+	DetailID       string `xml:"detail_id,omitempty"` // 付款单号
+	Status         string `xml:"status,omitempty"`    // SUCCESS FAILED PROCESSING
+	Reason         string `xml:"reason,omitempty"`
+	OpenID         string `xml:"openid,omitempty"`
+	TransferName   string `xml:"transfer_name,omitempty"`
+	PaymentAmount  Amount `xml:"payment_amount,omitempty"`
+	TransferTime   string `xml:"transfer_time,omitempty"`
+	PaymentTime    string `xml:"payment_time,omitempty"`
+	Desc           string `xml:"desc,omitempty"`
+}
+
+// Transfer 企业付款到零钱
+func (pcf *Pay) Transfer(p *TransferParams) (rsp TransferResponse, err error) {
+	if err = p.Amount.Validate("amount"); err != nil {
+		return
+	}
+	if p.CheckName == "" {
+		p.CheckName = CheckNameNo
+	}
+	if p.CheckName == CheckNameForce && p.ReUserName == "" {
+		err = fmt.Errorf("re_user_name is required when check_name is FORCE_CHECK")
+		return
+	}
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["mch_appid"] = pcf.AppID
+	param["mchid"] = pcf.PayMchID
+	param["device_info"] = p.DeviceInfo
+	param["nonce_str"] = nonceStr
+	param["partner_trade_no"] = p.PartnerTradeNo
+	param["openid"] = p.OpenID
+	param["check_name"] = p.CheckName
+	param["re_user_name"] = p.ReUserName
+	param["amount"] = p.Amount
+	param["desc"] = p.Desc
+	param["spbill_create_ip"] = p.CreateIP
+
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
+	str := orderParam(param, bizKey)
+	sign := util.MD5Sum(str)
+	request := transferRequest{
+		MchAppID:       pcf.AppID,
+		MchID:          pcf.PayMchID,
+		DeviceInfo:     p.DeviceInfo,
+		NonceStr:       nonceStr,
+		Sign:           sign,
+		PartnerTradeNo: p.PartnerTradeNo,
+		OpenID:         p.OpenID,
+		CheckName:      p.CheckName,
+		ReUserName:     p.ReUserName,
+		Amount:         p.Amount,
+		Desc:           p.Desc,
+		SpbillCreateIP: p.CreateIP,
+	}
+	rawRet, err := pcf.postXMLWithCert(transferGateway, request)
+	if err != nil {
+		return
+	}
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode == "SUCCESS" {
+		if rsp.ResultCode == "SUCCESS" {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("transfer error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		return
+	}
+	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	return
+}
+
+// QueryTransfer 查询企业付款到零钱
+func (pcf *Pay) QueryTransfer(partnerTradeNo string) (rsp QueryTransferResponse, err error) {
+	nonceStr := util.RandomStr(32)
+	param := make(map[string]interface{})
+	param["nonce_str"] = nonceStr
+	param["partner_trade_no"] = partnerTradeNo
+	param["mch_id"] = pcf.PayMchID
+	param["appid"] = pcf.AppID
+
+	payKey, err := pcf.signKey()
+	if err != nil {
+		return
+	}
+	bizKey := "&key=" + payKey
+	str := orderParam(param, bizKey)
+	sign := util.MD5Sum(str)
+	request := queryTransferRequest{
+		NonceStr:       nonceStr,
+		Sign:           sign,
+		PartnerTradeNo: partnerTradeNo,
+		MchID:          pcf.PayMchID,
+		AppID:          pcf.AppID,
+	}
+	rawRet, err := pcf.postXMLWithCert(queryTransferGateway, request)
+	if err != nil {
+		return
+	}
+	err = xml.Unmarshal(rawRet, &rsp)
+	if err != nil {
+		return
+	}
+	if rsp.ReturnCode == "SUCCESS" {
+		if rsp.ResultCode == "SUCCESS" {
+			err = nil
+			return
+		}
+		err = fmt.Errorf("query transfer error, errcode=%s,errmsg=%s", rsp.ErrCode, rsp.ErrCodeDes)
+		return
+	}
+	err = fmt.Errorf("[msg : xmlUnmarshalError] [rawReturn : %s]", string(rawRet))
+	return
+}
@@ -0,0 +1,42 @@
+package pay
+
+import (
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+)
+
+func TestTransferSign(t *testing.T) {
+	checkSign(t, []signCase{
+		{
+			name: "transfer",
+			param: map[string]interface{}{
+				"mch_appid":        "wxsp",
+				"mchid":            "1900000109",
+				"device_info":      "",
+				"nonce_str":        testNonceStr,
+				"partner_trade_no": "T20150806125346",
+				"openid":           "oUpF8uMuAJO_M2pxb1Q9zNjWeS6o",
+				"check_name":       CheckNameForce,
+				"re_user_name":     "张三",
+				"amount":           Amount(100),
+				"desc":             "理赔",
+				"spbill_create_ip": "127.0.0.1",
+			},
+			signType: SignTypeMD5,
+			want: "amount=100&check_name=FORCE_CHECK&desc=理赔&mch_appid=wxsp&mchid=1900000109&nonce_str=" + testNonceStr +
+				"&openid=oUpF8uMuAJO_M2pxb1Q9zNjWeS6o&partner_trade_no=T20150806125346&re_user_name=张三&spbill_create_ip=127.0.0.1",
+			wantSign: "16EFCDE362F1CF92A53343590FC0F8F6",
+		},
+	})
+}
+
+func TestTransferParamCheck(t *testing.T) {
+	pcf := NewPay(&context.Context{})
+	if _, err := pcf.Transfer(&TransferParams{Amount: 0}); err == nil {
+		t.Error("zero amount should be rejected")
+	}
+	if _, err := pcf.Transfer(&TransferParams{Amount: 100, CheckName: CheckNameForce}); err == nil {
+		t.Error("FORCE_CHECK without re_user_name should be rejected")
+	}
+}
@@ -95,23 +95,22 @@ func (srv *Server) getPay() (reply *message.Reply, err error) {
 			if srv.debug {
 				log.Warn("返回数据无法识别", srv.requestPayMsg)
 			}
-			return
+			return payFailReply("req_info invalid"), nil
 		}
 		rawXMLMsg, err = util.ECBDecrypt(encryptData, []byte(key2))
 		if err != nil || len(rawXMLMsg) == 0 {
 			if srv.debug {
 				log.Warn(srv.random, rawXMLMsg, err)
 			}
-			return
+			return payFailReply("req_info decrypt failed"), nil
 		}
-		err = xml.Unmarshal(rawXMLMsg, &srv.requestPayMsg)
-		if err != nil {
-			return
+		if err = xml.Unmarshal(rawXMLMsg, &srv.requestPayMsg); err != nil {
+			return payFailReply("req_info decrypt failed"), nil
 		}
 
 	} else if !pay.VerifySign(srv.PayKey, srv.requestPayMsg) {
-		log.Warn("验签失败", srv.PayKey, srv.requestPayMsg)
-		return
+		log.Warn("验签失败", srv.requestPayMsg)
+		return payFailReply("sign invalid"), nil
 	}
 	// 判断支付返回类型
 	if srv.requestPayMsg.RefundFee > 0 {
@@ -123,6 +122,15 @@ func (srv *Server) getPay() (reply *message.Reply, err error) {
 	return
 }
 
+// payFailReply 通知无法验签或解密时应答 FAIL，微信会重新通知，不回调业务
+func payFailReply(msg string) *message.Reply {
+	return &message.Reply{
+		ReplyScene:   message.ReplyScenePay,
+		ResponseType: message.ResponseTypeXML,
+		MsgData:      pay.NotifyResp{ReturnCode: "FAIL", ReturnMsg: msg},
+	}
+}
+
 func (srv *Server) getDouYinMessage() (reply *message.Reply, err error) {
 	var douYinEncryptData message.DouYinEncryptData
 	err = json.Unmarshal(srv.requestRaw, &douYinEncryptData)
@@ -147,8 +155,20 @@ func (srv *Server) getDouYinMessage() (reply *message.Reply, err error) {
 	}
 	//由于返回的没有appid 需要保存下来传入的appid
 	srv.requestMsgDouYin.AppID = srv.AppID
-	//
-	reply = srv.douYinMessageHandler(srv.requestMsgDouYin)
+	//抖音验证票据，无论业务如何回复都先保存
+	isTicket := srv.requestMsgDouYin.MsgType == message.EventTicket && srv.requestMsgDouYin.Event == message.MsgTypePush
+	if isTicket {
+		if err = srv.SaveComponentVerifyTicket(srv.requestMsgDouYin.Ticket); err != nil {
+			err = fmt.Errorf("保存票据失败, err=%v", err)
+			return
+		}
+	}
+	if srv.douYinMessageHandler != nil {
+		reply = srv.douYinMessageHandler(srv.requestMsgDouYin)
+	}
+	if reply == nil && isTicket {
+		reply = &message.Reply{ReplyScene: message.ReplySceneOpen}
+	}
 
 	return
 
@@ -287,6 +307,37 @@ func (srv *Server) getMessage() (reply *message.Reply, err error) {
 		}
 	}
 	err = xml.Unmarshal(srv.requestRaw, &srv.requestMsg)
-	reply = srv.messageHandler(srv.requestMsg)
+	if err != nil {
+		err = fmt.Errorf("解析消息失败, err=%v", err)
+		return
+	}
+	// 微信验证票据 /10min通知，无论业务如何回复都先保存
+	if srv.requestMsg.InfoType == message.InfoTypeVerifyTicket {
+		if err = srv.SaveComponentVerifyTicket(srv.requestMsg.ComponentVerifyTicket); err != nil {
+			err = fmt.Errorf("保存票据失败, err=%v", err)
+			return
+		}
+	}
+	// 配置了授权方存储时，自动记录授权、更新授权，删除取消授权的授权方
+	if srv.AuthorizerStore != nil {
+		switch srv.requestMsg.InfoType {
+		case message.InfoTypeAuthorized, message.InfoTypeUpdateAuthorized, message.InfoTypeUnauthorized:
+			err = srv.HandleAuthorizationEvent(string(srv.requestMsg.InfoType), srv.requestMsg.AuthorizerAppid, srv.requestMsg.AuthorizationCode)
+			if err != nil {
+				err = fmt.Errorf("处理授权事件失败, err=%v", err)
+				return
+			}
+			if srv.messageHandler == nil {
+				reply = &message.Reply{ReplyScene: message.ReplySceneOpen}
+				return
+			}
+		}
+	}
+	if srv.messageHandler != nil {
+		reply = srv.messageHandler(srv.requestMsg)
+	}
+	if reply == nil && srv.requestMsg.InfoType == message.InfoTypeVerifyTicket {
+		reply = &message.Reply{ReplyScene: message.ReplySceneOpen}
+	}
 	return
 }
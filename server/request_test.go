@@ -0,0 +1,39 @@
+package server
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pengshang1995/wechat-sdk/context"
+	"github.com/pengshang1995/wechat-sdk/message"
+	"github.com/pengshang1995/wechat-sdk/pay"
+)
+
+func TestServePayInvalidNotify(t *testing.T) {
+	bodies := map[string]string{
+		"bad sign":      `<xml><return_code>SUCCESS</return_code><result_code>SUCCESS</result_code><appid>wx123</appid><mch_id>10000</mch_id><total_fee>100</total_fee><sign>BAD</sign></xml>`,
+		"bad req_info":  `<xml><return_code>SUCCESS</return_code><appid>wx123</appid><mch_id>10000</mch_id><req_info>not base64!</req_info></xml>`,
+		"undecryptable": `<xml><return_code>SUCCESS</return_code><appid>wx123</appid><mch_id>10000</mch_id><req_info>AAAAAAAAAAAAAAAAAAAAAA==</req_info></xml>`,
+	}
+	for name, body := range bodies {
+		w := httptest.NewRecorder()
+		srv := NewServer(&context.Context{
+			PayKey:  "key",
+			Request: httptest.NewRequest("POST", "/notify", strings.NewReader(body)),
+			Writer:  w,
+		})
+		called := false
+		srv.SetPayHandler(func(pay.NotifyResult) *message.Reply {
+			called = true
+			return nil
+		})
+		if err := srv.Serve(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		srv.Send()
+		if called || !strings.Contains(w.Body.String(), "FAIL") {
+			t.Errorf("%s: expect FAIL without callback, called=%v, body=%s", name, called, w.Body.String())
+		}
+	}
+}
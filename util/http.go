@@ -10,7 +10,6 @@ import (
 	"golang.org/x/crypto/pkcs12"
 	"io"
 	"io/ioutil"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -20,6 +19,9 @@ import (
 
 const PROXY_URL = "http://net-proxy-pub-test.sftcwl.com:1080"
 
+// HTTPTransport 非空时接口请求使用该 Transport 代替默认的代理（不影响携带商户证书的请求），可用于自定义代理或测试
+var HTTPTransport http.RoundTripper
+
 // HTTPGet get 请求
 func HTTPGet(uri string) ([]byte, error) {
 
@@ -50,6 +52,9 @@ func HTTPGetNoProxy(uri string) ([]byte, error) {
 }
 
 func getProxy() *http.Client {
+	if HTTPTransport != nil {
+		return &http.Client{Transport: HTTPTransport, Timeout: time.Second * 5}
+	}
 	proxy, _ := url.Parse(PROXY_URL)
 	tr := &http.Transport{
 		Proxy:           http.ProxyURL(proxy),
@@ -225,8 +230,15 @@ func PostXML(uri string, obj interface{}) ([]byte, error) {
 
 // httpWithTLS CA证书
 func httpWithTLS(p12 []byte, key string) (*http.Client, error) {
-	var client *http.Client
-	cert := pkcs12ToPem(p12, key)
+	cert, err := pkcs12ToPem(p12, key)
+	if err != nil {
+		return nil, err
+	}
+	return NewTLSClient(cert), nil
+}
+
+// NewTLSClient 使用客户端证书创建 http 客户端，可复用于所有需要证书的请求
+func NewTLSClient(cert tls.Certificate) *http.Client {
 	config := &tls.Config{
 		Certificates: []tls.Certificate{cert},
 	}
@@ -234,44 +246,62 @@ func httpWithTLS(p12 []byte, key string) (*http.Client, error) {
 		TLSClientConfig:    config,
 		DisableCompression: true,
 	}
-	client = &http.Client{Transport: tr}
-	return client, nil
+	return &http.Client{Transport: tr}
+}
+
+// LoadP12Cert 加载 P12 格式的证书
+func LoadP12Cert(p12 []byte, password string) (tls.Certificate, error) {
+	return pkcs12ToPem(p12, password)
+}
+
+// LoadPEMCert 加载 PEM 格式的证书和私钥
+func LoadPEMCert(certPEM, keyPEM []byte) (tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("load pem cert error, err=%v", err)
+	}
+	return cert, nil
 }
 
 // pkcs12ToPem 将Pkcs12转成Pem
-func pkcs12ToPem(p12 []byte, password string) tls.Certificate {
+func pkcs12ToPem(p12 []byte, password string) (cert tls.Certificate, err error) {
+	if len(p12) == 0 {
+		err = fmt.Errorf("p12 cert is empty")
+		return
+	}
 	blocks, err := pkcs12.ToPEM(p12, password)
-	defer func() {
-		if x := recover(); x != nil {
-			log.Print(x)
-		}
-	}()
 	if err != nil {
-		panic(err)
+		err = fmt.Errorf("decode p12 cert error, err=%v", err)
+		return
 	}
 	var pemData []byte
 	for _, b := range blocks {
 		pemData = append(pemData, pem.EncodeToMemory(b)...)
 	}
-	cert, err := tls.X509KeyPair(pemData, pemData)
+	cert, err = tls.X509KeyPair(pemData, pemData)
 	if err != nil {
-		panic(err)
+		err = fmt.Errorf("load p12 cert error, err=%v", err)
 	}
-	return cert
+	return
 }
 
 // PostXMLWithTLS perform a HTTP/POST request with XML body and TLS
 func PostXMLWithTLS(uri string, obj interface{}, p12 []byte, key string) ([]byte, error) {
-	xmlData, err := xml.Marshal(obj)
+	client, err := httpWithTLS(p12, key)
 	if err != nil {
 		return nil, err
 	}
+	return PostXMLWithClient(client, uri, obj)
+}
 
-	body := bytes.NewBuffer(xmlData)
-	client, err := httpWithTLS(p12, key)
+// PostXMLWithClient perform a HTTP/POST request with XML body using the given client
+func PostXMLWithClient(client *http.Client, uri string, obj interface{}) ([]byte, error) {
+	xmlData, err := xml.Marshal(obj)
 	if err != nil {
 		return nil, err
 	}
+
+	body := bytes.NewBuffer(xmlData)
 	response, err := client.Post(uri, "application/xml;charset=utf-8", body)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,15 @@
+package util
+
+import "testing"
+
+func TestLoadInvalidCert(t *testing.T) {
+	if _, err := LoadP12Cert(nil, "mchid"); err == nil {
+		t.Error("expect error for empty p12")
+	}
+	if _, err := LoadP12Cert([]byte("not a p12"), "mchid"); err == nil {
+		t.Error("expect error for invalid p12")
+	}
+	if _, err := LoadPEMCert([]byte("cert"), []byte("key")); err == nil {
+		t.Error("expect error for invalid pem")
+	}
+}
@@ -1,6 +1,8 @@
 package wechat
 
 import (
+	"fmt"
+	"log"
 	"github.com/pengshang1995/wechat-sdk/device"
 	"github.com/pengshang1995/wechat-sdk/message"
 	"github.com/pengshang1995/wechat-sdk/open"
@@ -37,17 +39,40 @@ type Config struct {
 	PayNotifyURL string // 支付 - 接受微信支付结果通知的接口地址
 	PayKey       string // 支付 - 商户后台设置的支付 key
 	P12          []byte // 支付 - 商户证书文件
+	PayCertPEM   []byte // 支付 - PEM 格式商户证书，与 PayKeyPEM 一起配置时优先于 P12
+	PayKeyPEM    []byte // 支付 - PEM 格式商户证书私钥
+	PaySandbox   bool   // 支付 - 是否开启仿真测试模式
 
-	Cache cache.Cache
+	Cache          cache.Cache
+	CacheNamespace string // 缓存 key 的命名空间，多个环境或租户共用一个缓存时设置不同的值，如 "prod"
+
+	AuthorizerStore context.AuthorizerStore // 第三方平台 - 授权方存储，设置后自动处理授权事件推送
 }
 
 // NewWechat init
+// 配置了商户证书时立即加载校验，证书有误时记录日志，需要在启动时返回错误请使用 NewWechatWithPayCert
 func NewWechat(cfg *Config) *Wechat {
 	context := new(context.Context)
 	copyConfigToContext(cfg, context)
+	if context.HasPayCert() {
+		if err := context.LoadPayCert(); err != nil {
+			log.Printf("load pay cert error, err=%v", err)
+		}
+	}
 	return &Wechat{context}
 }
 
+// NewWechatWithPayCert 创建实例并加载校验商户证书，证书有误或未配置时返回错误
+// 加载后退款、分账、企业付款等需要证书的接口复用同一个 TLS 客户端
+func NewWechatWithPayCert(cfg *Config) (*Wechat, error) {
+	context := new(context.Context)
+	copyConfigToContext(cfg, context)
+	if err := context.LoadPayCert(); err != nil {
+		return nil, err
+	}
+	return &Wechat{context}, nil
+}
+
 func copyConfigToContext(cfg *Config, context *context.Context) {
 	context.AppID = cfg.AppID
 	context.AppSecret = cfg.AppSecret
@@ -58,6 +83,11 @@ func copyConfigToContext(cfg *Config, context *context.Context) {
 	context.PayNotifyURL = cfg.PayNotifyURL
 	context.Cache = cfg.Cache
 	context.P12 = cfg.P12
+	context.PayCertPEM = cfg.PayCertPEM
+	context.PayKeyPEM = cfg.PayKeyPEM
+	context.PaySandbox = cfg.PaySandbox
+	context.CacheNamespace = cfg.CacheNamespace
+	context.AuthorizerStore = cfg.AuthorizerStore
 	context.SetAccessTokenLock(new(sync.RWMutex))
 	context.SetJsAPITicketLock(new(sync.RWMutex))
 }
@@ -84,6 +114,17 @@ func (wc *Wechat) GetOpen() *open.Open {
 	return open.NewOpen(wc.Context)
 }
 
+// GetAuthorizerWechat 第三方平台代授权的公众号/小程序调用接口，需配置 AuthorizerStore
+func (wc *Wechat) GetAuthorizerWechat(appid string) (*Wechat, error) {
+	if wc.Context.AuthorizerStore == nil {
+		return nil, fmt.Errorf("authorizer store is not set")
+	}
+	if _, err := wc.Context.AuthorizerStore.GetAuthorizer(appid); err != nil {
+		return nil, err
+	}
+	return &Wechat{wc.Context.NewAuthorizerContext(appid)}, nil
+}
+
 // GetMaterial 素材管理
 func (wc *Wechat) GetMaterial() *material.Material {
 	return material.NewMaterial(wc.Context)